@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -20,6 +21,9 @@ func SetDefaults() {
 	viper.SetDefault("api-base", "https://api.ficsit.dev")
 	viper.SetDefault("graphql-api", "/v2/query")
 	viper.SetDefault("concurrent-downloads", 5)
+	viper.SetDefault("ftp-connections", 5)
+	viper.SetDefault("connect-timeout", 10*time.Second)
+	viper.SetDefault("read-timeout", 30*time.Second)
 
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
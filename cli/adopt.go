@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// AdoptedMod is a mod found in the Mods directory of an adopted installation
+type AdoptedMod struct {
+	Reference string
+	Version   string
+	Location  string
+	// Reason explains why the mod was not added to the profile, or why it will be reinstalled
+	Reason string
+	// Added is true if the mod was found in the repository and added to the profile
+	Added bool
+	// Managed is true if the files of the mod match the repository, so it is not reinstalled on the next apply
+	Managed bool
+}
+
+// Adopt creates a profile from the mods found in the Mods directory of the installation, pinned to their installed versions,
+// and adds the installation with that profile if it was not added yet.
+// Mods of which the files match the repository are marked as installed by ficsit-cli, the others are reinstalled on the next apply.
+func (i *Installations) Adopt(ctx context.Context, global *GlobalContext, installPath string, profileName string) (*Installation, []*AdoptedMod, error) {
+	if global.Profiles.GetProfile(profileName) != nil {
+		return nil, nil, fmt.Errorf("profile with name %s already exists", profileName)
+	}
+
+	profile, err := global.Profiles.AddProfile(profileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	installation := i.FindInstallation(installPath)
+	if installation == nil {
+		installation, err = i.AddInstallation(ctx, global, installPath, profileName)
+	} else {
+		err = installation.SetProfile(global, profileName)
+	}
+
+	if err != nil {
+		_ = global.Profiles.DeleteProfile(profileName)
+		return nil, nil, err
+	}
+
+	mods, err := installation.adoptMods(ctx, global, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return installation, mods, nil
+}
+
+func (i *Installation) adoptMods(ctx context.Context, global *GlobalContext, profile *Profile) ([]*AdoptedMod, error) {
+	platform, err := i.GetPlatform(ctx, global)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	var mods []*AdoptedMod
+	for _, modRoot := range modRoots {
+		rootMods, err := findModDirectories(ctx, d, modsDirectory, modRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		mods = append(mods, rootMods...)
+	}
+
+	for _, mod := range mods {
+		if mod.Reason != "" {
+			continue
+		}
+
+		if err := i.adoptMod(ctx, global, platform, d, modsDirectory, profile, mod); err != nil {
+			return nil, fmt.Errorf("failed to adopt %s: %w", mod.Reference, err)
+		}
+	}
+
+	return mods, nil
+}
+
+// findModDirectories returns the mods in the mod root of the Mods directory, with the version of their .uplugin file
+func findModDirectories(ctx context.Context, d disk.Disk, modsDirectory string, modRoot string) ([]*AdoptedMod, error) {
+	rootDirectory := filepath.Join(modsDirectory, modRoot)
+
+	exists, err := d.Exists(ctx, rootDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	entries, err := d.ReadDir(ctx, rootDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mods directory: %w", err)
+	}
+
+	var mods []*AdoptedMod
+	for _, entry := range entries {
+		if !entry.IsDir() || slices.Contains(modRoots, entry.Name()) {
+			continue
+		}
+
+		mod := &AdoptedMod{
+			Reference: entry.Name(),
+			Location:  filepath.Join(modRoot, entry.Name()),
+		}
+		mods = append(mods, mod)
+
+		upluginPath := filepath.Join(rootDirectory, entry.Name(), entry.Name()+".uplugin")
+
+		exists, err := d.Exists(ctx, upluginPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			mod.Reason = "no " + entry.Name() + ".uplugin file"
+			continue
+		}
+
+		data, err := d.Read(ctx, upluginPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read uplugin file: %w", err)
+		}
+
+		var uplugin cache.UPlugin
+		if err := json.Unmarshal(data, &uplugin); err != nil {
+			mod.Reason = "invalid uplugin file: " + err.Error()
+			continue
+		}
+
+		if !utils.SemVerRegex.MatchString(uplugin.SemVersion) {
+			mod.Reason = "invalid version in uplugin file: " + uplugin.SemVersion
+			continue
+		}
+
+		mod.Version = uplugin.SemVersion
+	}
+
+	return mods, nil
+}
+
+func (i *Installation) adoptMod(ctx context.Context, global *GlobalContext, platform *Platform, d disk.Disk, modsDirectory string, profile *Profile, mod *AdoptedMod) error {
+	versions, err := global.Provider.ModVersionsWithDependencies(ctx, mod.Reference)
+	if err != nil && !errors.Is(err, provider.ErrModNotFound) {
+		return fmt.Errorf("failed to fetch mod: %w", err)
+	}
+
+	if len(versions) == 0 {
+		slog.Info("mod not found in the repository", slog.String("mod_reference", mod.Reference), slog.Any("err", err))
+		mod.Reason = "not found in the repository"
+		return nil
+	}
+
+	index := slices.IndexFunc(versions, func(version resolver.ModVersion) bool {
+		return version.Version == mod.Version
+	})
+	if index == -1 {
+		mod.Reason = "version " + mod.Version + " not found in the repository"
+		return nil
+	}
+
+	if err := profile.AddMod(mod.Reference, mod.Version); err != nil {
+		return err
+	}
+
+	mod.Added = true
+
+	targetIndex := slices.IndexFunc(versions[index].Targets, func(target resolver.Target) bool {
+		return string(target.TargetName) == platform.TargetName
+	})
+	if targetIndex == -1 {
+		mod.Reason = "not available for " + platform.TargetName
+		return nil
+	}
+
+	target := versions[index].Targets[targetIndex]
+
+	downloadSemaphore := make(chan int, viper.GetInt("concurrent-downloads"))
+	defer close(downloadSemaphore)
+
+	archive, size, err := openModArchive(ctx, mod.Reference, mod.Version, target.Link, target.Hash, platform.TargetName, nil, downloadSemaphore)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return fmt.Errorf("failed to read file as zip: %w", err)
+	}
+
+	location, err := getExtractLocation(reader, mod.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to determine extract location: %w", err)
+	}
+
+	if location != mod.Location {
+		mod.Reason = "installed in " + mod.Location + " instead of " + location + ", remove it before applying"
+		return nil
+	}
+
+	managed, err := utils.AdoptMod(ctx, reader, filepath.Join(modsDirectory, location), target.Hash, d)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	mod.Managed = managed
+	if !managed {
+		mod.Reason = "files differ from the repository, it will be reinstalled"
+	}
+
+	return nil
+}
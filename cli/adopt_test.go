@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+)
+
+func TestAdoptInstallation(t *testing.T) {
+	ctx, installation, d := newMemoryInstallation(t, "AdoptTest")
+
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	// Pretend the mods were installed by hand, one of them modified, next to mods which are not in the repository
+	for _, file := range []string{"SML/.smm", "SML/.smm-manifest", "AreaActions/.smm", "AreaActions/.smm-manifest"} {
+		testza.AssertNoError(t, d.Remove(context.Background(), "/server/FactoryGame/Mods/"+file))
+	}
+
+	testza.AssertNoError(t, d.Write(context.Background(), "/server/FactoryGame/Mods/AreaActions/Binaries/LinuxServer/AreaActions.txt", []byte("patched")))
+	testza.AssertNoError(t, d.MkDir(context.Background(), "/server/FactoryGame/Mods/Custom"))
+	testza.AssertNoError(t, d.Write(context.Background(), "/server/FactoryGame/Mods/Custom/Custom.uplugin", []byte(`{"SemVersion": "1.0.0"}`)))
+	testza.AssertNoError(t, d.MkDir(context.Background(), "/server/FactoryGame/Mods/NoPlugin"))
+
+	adopted, mods, err := ctx.Installations.Adopt(context.Background(), ctx, installation.Path, "Adopted")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, installation, adopted)
+	testza.AssertEqual(t, "Adopted", installation.Profile)
+	testza.AssertLen(t, mods, 4)
+
+	byReference := make(map[string]*AdoptedMod)
+	for _, mod := range mods {
+		byReference[mod.Reference] = mod
+	}
+
+	testza.AssertTrue(t, byReference["SML"].Managed)
+	testza.AssertEqual(t, "3.6.0", byReference["SML"].Version)
+	testza.AssertTrue(t, byReference["AreaActions"].Added)
+	testza.AssertFalse(t, byReference["AreaActions"].Managed)
+	testza.AssertFalse(t, byReference["Custom"].Added)
+	testza.AssertEqual(t, "not found in the repository", byReference["Custom"].Reason)
+	testza.AssertFalse(t, byReference["NoPlugin"].Added)
+
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/SML/.smm")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	profile := ctx.Profiles.GetProfile("Adopted")
+	testza.AssertEqual(t, "1.0.0", profile.Mods["AreaActions"].Version)
+	testza.AssertEqual(t, "3.6.0", profile.Mods["SML"].Version)
+	testza.AssertFalse(t, profile.HasMod("Custom"))
+
+	_, _, err = ctx.Installations.Adopt(context.Background(), ctx, installation.Path, "Adopted")
+	testza.AssertNotNil(t, err)
+
+	// Only mods missing from the repository are skipped, other errors stop the adoption
+	apiBase := viper.GetString("api-base")
+	viper.Set("api-base", "http://127.0.0.1:0")
+	_, _, err = ctx.Installations.Adopt(context.Background(), ctx, installation.Path, "Unreachable")
+	viper.Set("api-base", apiBase)
+	testza.AssertNotNil(t, err)
+	testza.AssertNoError(t, installation.SetProfile(ctx, "Adopted"))
+
+	// Modified mods are reinstalled, unknown mods are kept
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	data, err := d.Read(context.Background(), "/server/FactoryGame/Mods/AreaActions/Binaries/LinuxServer/AreaActions.txt")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "AreaActions 1.0.0 LinuxServer", string(data))
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/Custom/Custom.uplugin")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+}
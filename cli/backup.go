@@ -0,0 +1,404 @@
+package cli
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+const (
+	backupTimeFormat = "20060102-150405.000"
+	// automaticBackupPrefix marks the backups made before applying, which are pruned separately
+	automaticBackupPrefix = "auto-"
+)
+
+// Backup is a local archive of the Mods directory of an installation
+type Backup struct {
+	Time time.Time
+	Name string
+	Path string
+	// Size is the size of the archive
+	Size int64
+	// ContentSize is the size of the backed up files
+	ContentSize int64
+	Automatic   bool
+}
+
+func (i *Installation) backupsDir() string {
+	return filepath.Join(viper.GetString("local-dir"), "backups", i.localKey())
+}
+
+// Backups returns the backups of the installation, oldest first
+func (i *Installation) Backups() ([]*Backup, error) {
+	entries, err := os.ReadDir(i.backupsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+
+	var backups []*Backup
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".zip" {
+			continue
+		}
+
+		backup, err := readBackup(filepath.Join(i.backupsDir(), entry.Name()))
+		if err != nil {
+			slog.Warn("skipping invalid backup", slog.String("name", entry.Name()), slog.Any("err", err))
+			continue
+		}
+
+		backups = append(backups, backup)
+	}
+
+	slices.SortFunc(backups, func(a, b *Backup) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return backups, nil
+}
+
+func readBackup(backupPath string) (*Backup, error) {
+	name := strings.TrimSuffix(filepath.Base(backupPath), ".zip")
+	automatic := strings.HasPrefix(name, automaticBackupPrefix)
+
+	backupTime, err := time.Parse(backupTimeFormat, strings.TrimPrefix(name, automaticBackupPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup time: %w", err)
+	}
+
+	reader, err := zip.OpenReader(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer reader.Close()
+
+	backup := &Backup{
+		Time:      backupTime,
+		Name:      name,
+		Path:      backupPath,
+		Automatic: automatic,
+	}
+
+	for _, file := range reader.File {
+		backup.ContentSize += int64(file.UncompressedSize64)
+	}
+
+	stat, err := os.Stat(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	backup.Size = stat.Size()
+
+	return backup, nil
+}
+
+// Backup archives the Mods directory of the installation, including the lockfile and the .smm markers of the mods
+func (i *Installation) Backup(ctx context.Context) (*Backup, error) {
+	return i.backup(ctx, false)
+}
+
+func (i *Installation) backup(ctx context.Context, automatic bool) (*Backup, error) {
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	exists, err := d.Exists(ctx, modsDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, errors.New("installation has no Mods directory")
+	}
+
+	if err := os.MkdirAll(i.backupsDir(), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backups directory: %w", err)
+	}
+
+	name := time.Now().UTC().Format(backupTimeFormat)
+	if automatic {
+		name = automaticBackupPrefix + name
+	}
+
+	backupPath := filepath.Join(i.backupsDir(), name+".zip")
+	partialPath := backupPath + ".partial"
+
+	slog.Info("backing up installation", slog.String("path", i.Path), slog.String("backup", backupPath))
+
+	if err := writeBackup(ctx, d, modsDirectory, partialPath); err != nil {
+		_ = os.Remove(partialPath)
+		return nil, err
+	}
+
+	if err := os.Rename(partialPath, backupPath); err != nil {
+		_ = os.Remove(partialPath)
+		return nil, fmt.Errorf("failed to move backup: %w", err)
+	}
+
+	return readBackup(backupPath)
+}
+
+func writeBackup(ctx context.Context, d disk.Disk, modsDirectory string, backupPath string) error {
+	f, err := os.Create(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+
+	if err := addBackupDirectory(ctx, d, writer, modsDirectory, ""); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return nil
+}
+
+func addBackupDirectory(ctx context.Context, d disk.Disk, writer *zip.Writer, directory string, prefix string) error {
+	entries, err := d.ReadDir(ctx, directory)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", directory, err)
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("backup cancelled: %w", err)
+		}
+
+		name := path.Join(prefix, entry.Name())
+		fullPath := filepath.Join(directory, entry.Name())
+
+		if entry.IsDir() {
+			if _, err := writer.Create(name + "/"); err != nil {
+				return fmt.Errorf("failed to add directory %s: %w", name, err)
+			}
+
+			if err := addBackupDirectory(ctx, d, writer, fullPath, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := addBackupFile(ctx, d, writer, fullPath, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addBackupFile(ctx context.Context, d disk.Disk, writer *zip.Writer, fullPath string, name string) error {
+	reader, err := d.OpenReader(ctx, fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", fullPath, err)
+	}
+	defer reader.Close()
+
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+
+	if stat, err := d.Stat(ctx, fullPath); err == nil {
+		header.Modified = stat.ModTime
+	}
+
+	out, err := writer.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(out, reader); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", fullPath, err)
+	}
+
+	return nil
+}
+
+// FindBackup returns the backup with the name, or the latest backup if the name is empty
+func (i *Installation) FindBackup(name string) (*Backup, error) {
+	backups, err := i.Backups()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) == 0 {
+		return nil, errors.New("installation has no backups")
+	}
+
+	if name == "" {
+		return backups[len(backups)-1], nil
+	}
+
+	for _, backup := range backups {
+		if backup.Name == name {
+			return backup, nil
+		}
+	}
+
+	return nil, fmt.Errorf("backup %s not found", name)
+}
+
+// Restore replaces the Mods directory of the installation with the backup.
+// The previous Mods directory is put back if the backup cannot be extracted.
+// Refuses to restore while the game or server is running, like applying.
+func (i *Installation) Restore(ctx context.Context, global *GlobalContext, backup *Backup, options InstallOptions) error {
+	platform, err := i.GetPlatform(ctx, global)
+	if err != nil {
+		return fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	if err := i.checkRunning(ctx, global, platform, options, nil); err != nil {
+		return err
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("restoring installation", slog.String("path", i.Path), slog.String("backup", backup.Name))
+
+	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
+
+	exists, err := d.Exists(ctx, modsDirectory)
+	if err != nil {
+		return err
+	}
+
+	// The current Mods directory is archived first, so that it can be put back if the backup cannot be extracted
+	previousPath := ""
+	if exists {
+		if err := os.MkdirAll(i.backupsDir(), 0o755); err != nil {
+			return fmt.Errorf("failed to create backups directory: %w", err)
+		}
+
+		previousPath = filepath.Join(i.backupsDir(), "restore-"+time.Now().UTC().Format(backupTimeFormat)+".zip.partial")
+		defer os.Remove(previousPath)
+
+		if err := writeBackup(ctx, d, modsDirectory, previousPath); err != nil {
+			return fmt.Errorf("failed to back up Mods directory: %w", err)
+		}
+	}
+
+	if err := replaceMods(ctx, d, modsDirectory, backup.Path); err != nil {
+		if previousPath == "" {
+			_ = d.Remove(context.WithoutCancel(ctx), modsDirectory)
+			return fmt.Errorf("failed to restore backup: %w", err)
+		}
+
+		slog.Warn("putting back the previous Mods directory", slog.String("path", i.Path), slog.Any("err", err))
+
+		if previousErr := replaceMods(context.WithoutCancel(ctx), d, modsDirectory, previousPath); previousErr != nil {
+			return fmt.Errorf("failed to restore backup: %w, and failed to put back the previous Mods directory: %w", err, previousErr)
+		}
+
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	return nil
+}
+
+// replaceMods replaces the Mods directory with the content of the archive
+func replaceMods(ctx context.Context, d disk.Disk, modsDirectory string, archivePath string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer reader.Close()
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer archive.Close()
+
+	if err := d.Remove(ctx, modsDirectory); err != nil {
+		return fmt.Errorf("failed removing Mods directory: %w", err)
+	}
+
+	if err := d.MkDir(ctx, modsDirectory); err != nil {
+		return fmt.Errorf("failed creating Mods directory: %w", err)
+	}
+
+	if err := utils.ExtractArchive(ctx, &reader.Reader, archive, modsDirectory, nil, d); err != nil {
+		return fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	return nil
+}
+
+// PruneBackups deletes the oldest manual or automatic backups, keeping the newest ones.
+// Returns the deleted backups.
+func (i *Installation) PruneBackups(keep int, automatic bool) ([]*Backup, error) {
+	backups, err := i.Backups()
+	if err != nil {
+		return nil, err
+	}
+
+	backups = slices.DeleteFunc(backups, func(backup *Backup) bool {
+		return backup.Automatic != automatic
+	})
+
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	pruned := backups[:len(backups)-keep]
+	for _, backup := range pruned {
+		slog.Info("deleting backup", slog.String("path", i.Path), slog.String("backup", backup.Name))
+
+		if err := os.Remove(backup.Path); err != nil {
+			return nil, fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
+		}
+	}
+
+	return pruned, nil
+}
+
+// autoBackup backs up the installation before an apply updates or removes mods, if enabled for the installation
+func (i *Installation) autoBackup(ctx context.Context, changes *HookChanges) error {
+	if i.AutoBackups <= 0 || (len(changes.Updated) == 0 && len(changes.Removed) == 0) {
+		return nil
+	}
+
+	if _, err := i.backup(ctx, true); err != nil {
+		return fmt.Errorf("failed to back up installation: %w", err)
+	}
+
+	if _, err := i.PruneBackups(i.AutoBackups, true); err != nil {
+		return err
+	}
+
+	return nil
+}
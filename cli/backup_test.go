@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestBackupRestore(t *testing.T) {
+	ctx, installation, d := newMemoryInstallation(t, "BackupTest")
+	t.Cleanup(func() {
+		testza.AssertNoError(t, os.RemoveAll(installation.backupsDir()))
+	})
+
+	_, err := installation.Backup(context.Background())
+	testza.AssertNotNil(t, err)
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	backup, err := installation.Backup(context.Background())
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, backup.Automatic)
+	testza.AssertGreater(t, backup.Size, 0)
+	testza.AssertGreater(t, backup.ContentSize, 0)
+
+	// Removing mods makes an automatic backup first
+	installation.AutoBackups = 1
+	ctx.Profiles.Profiles["BackupTest"].RemoveMod("AreaActions")
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	backups, err := installation.Backups()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, backups, 2)
+	testza.AssertTrue(t, backups[1].Automatic)
+	testza.AssertEqual(t, backup.ContentSize, backups[1].ContentSize)
+
+	// Restores the mods, their markers and the lockfile
+	restored, err := installation.FindBackup(backup.Name)
+	testza.AssertNoError(t, err)
+
+	err = installation.Restore(context.Background(), ctx, restored, InstallOptions{})
+	testza.AssertNoError(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions/.smm")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	lockfile, err := installation.LockFile(context.Background(), ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.0.0", lockfile.Mods["AreaActions"].Version)
+
+	// A backup which cannot be extracted leaves the Mods directory as it was
+	brokenPath := filepath.Join(t.TempDir(), "broken.zip")
+	broken, err := os.Create(brokenPath)
+	testza.AssertNoError(t, err)
+	writer := zip.NewWriter(broken)
+	entry, err := writer.CreateRaw(&zip.FileHeader{
+		Name:               "Broken/file.txt",
+		Method:             zip.Store,
+		CRC32:              1,
+		CompressedSize64:   4,
+		UncompressedSize64: 4,
+	})
+	testza.AssertNoError(t, err)
+	_, err = entry.Write([]byte("test"))
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, writer.Close())
+	testza.AssertNoError(t, broken.Close())
+
+	err = installation.Restore(context.Background(), ctx, &Backup{Name: "broken", Path: brokenPath}, InstallOptions{})
+	testza.AssertNotNil(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions/.smm")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/Broken")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	// Manual and automatic backups are pruned separately
+	pruned, err := installation.PruneBackups(0, false)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, pruned, 1)
+
+	latest, err := installation.FindBackup("")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, latest.Automatic)
+
+	_, err = installation.FindBackup(backup.Name)
+	testza.AssertNotNil(t, err)
+}
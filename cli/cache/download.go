@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +11,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,7 +33,29 @@ type downloadGroup struct {
 
 var downloadSync = xsync.NewMapOf[string, *downloadGroup]()
 
-func DownloadOrCache(cacheKey string, hash string, url string, updates chan<- utils.GenericProgress, downloadSemaphore chan int) (*os.File, int64, error) {
+// retryDelay is the delay between download attempts
+var retryDelay = time.Second
+
+// HashMismatchError is returned when a downloaded file does not match the expected hash.
+// The file is moved to the quarantine directory instead of being added to the cache,
+// except for archives of static repositories on this machine, which are left in place.
+type HashMismatchError struct {
+	CacheKey       string
+	URL            string
+	Expected       string
+	Actual         string
+	QuarantinePath string
+}
+
+func (e *HashMismatchError) Error() string {
+	if e.QuarantinePath == "" {
+		return fmt.Sprintf("hash mismatch for %s from %s: expected %s, got %s", e.CacheKey, e.URL, e.Expected, e.Actual)
+	}
+
+	return fmt.Sprintf("hash mismatch for %s from %s: expected %s, got %s (quarantined at %s)", e.CacheKey, e.URL, e.Expected, e.Actual, e.QuarantinePath)
+}
+
+func DownloadOrCache(ctx context.Context, cacheKey string, hash string, expectedSize int64, url string, updates chan<- utils.GenericProgress, downloadSemaphore chan int) (*os.File, int64, error) {
 	group, loaded := downloadSync.LoadOrCompute(cacheKey, func() *downloadGroup {
 		return &downloadGroup{
 			hash:    hash,
@@ -58,7 +85,11 @@ func DownloadOrCache(cacheKey string, hash string, url string, updates chan<- ut
 			return nil, 0, errors.New("hash mismatch in download group")
 		}
 
-		<-group.wait
+		select {
+		case <-group.wait:
+		case <-ctx.Done():
+			return nil, 0, ctx.Err() //nolint:wrapcheck
+		}
 
 		if group.err != nil {
 			return nil, 0, group.err
@@ -102,19 +133,41 @@ func DownloadOrCache(cacheKey string, hash string, url string, updates chan<- ut
 	}()
 
 	var size int64
+	var mismatches int
 
 	err := retry.Do(func() error {
 		var err error
-		size, err = downloadInternal(cacheKey, location, hash, url, upstreamUpdates, downloadSemaphore)
+		size, err = downloadInternal(ctx, cacheKey, location, hash, expectedSize, url, upstreamUpdates, downloadSemaphore)
 		if err != nil {
 			return fmt.Errorf("internal download error: %w", err)
 		}
 		return nil
 	},
 		retry.Attempts(5),
-		retry.Delay(time.Second),
+		retry.Delay(retryDelay),
 		retry.DelayType(retry.FixedDelay),
+		retry.LastErrorOnly(true),
+		retry.Context(ctx),
+		retry.RetryIf(func(err error) bool {
+			// A mismatch is only retried once, in case a resumed partial file was corrupted,
+			// as the full download will not match either
+			var mismatch *HashMismatchError
+			if errors.As(err, &mismatch) {
+				mismatches++
+				if mismatches > 1 {
+					return false
+				}
+			}
+
+			// Cancelled downloads keep their partial file, and are resumed by the next attempt
+			return ctx.Err() == nil
+		}),
 		retry.OnRetry(func(n uint, err error) {
+			var mismatch *HashMismatchError
+			if errors.As(err, &mismatch) {
+				slog.Warn("downloaded file does not match hash, retrying", slog.Uint64("n", uint64(n)), slog.String("cacheKey", cacheKey), slog.String("expected", mismatch.Expected), slog.String("actual", mismatch.Actual), slog.String("quarantine", mismatch.QuarantinePath))
+				return
+			}
 			if n > 0 {
 				slog.Info("retrying download", slog.Uint64("n", uint64(n)), slog.String("cacheKey", cacheKey))
 			}
@@ -140,7 +193,7 @@ func DownloadOrCache(cacheKey string, hash string, url string, updates chan<- ut
 	return f, size, nil
 }
 
-func downloadInternal(cacheKey string, location string, hash string, url string, updates chan<- utils.GenericProgress, downloadSemaphore chan int) (int64, error) {
+func downloadInternal(ctx context.Context, cacheKey string, location string, hash string, expectedSize int64, url string, updates chan<- utils.GenericProgress, downloadSemaphore chan int) (int64, error) {
 	stat, err := os.Stat(location)
 	if err == nil {
 		matches, err := compareHash(hash, location)
@@ -159,50 +212,157 @@ func downloadInternal(cacheKey string, location string, hash string, url string,
 		return 0, fmt.Errorf("failed to stat file: %s: %w", location, err)
 	}
 
-	if updates != nil {
-		headResp, err := http.Head(url)
-		if err != nil {
-			return 0, fmt.Errorf("failed to head: %s: %w", url, err)
+	if downloadSemaphore != nil {
+		select {
+		case downloadSemaphore <- 1:
+		case <-ctx.Done():
+			return 0, ctx.Err() //nolint:wrapcheck
 		}
-		defer headResp.Body.Close()
-		updates <- utils.GenericProgress{Total: headResp.ContentLength}
+		defer func() { <-downloadSemaphore }()
 	}
 
-	if downloadSemaphore != nil {
-		downloadSemaphore <- 1
-		defer func() { <-downloadSemaphore }()
+	partLocation := partialLocation(cacheKey)
+	validatorLocation := partLocation + ".validator"
+
+	if err := os.MkdirAll(filepath.Dir(partLocation), 0o777); err != nil {
+		return 0, fmt.Errorf("failed creating partial downloads directory: %w", err)
 	}
 
-	out, err := os.Create(location)
+	// Resume a previous partial download, if the server can confirm it is still the same file
+	var offset int64
+	var validator string
+	if partStat, err := os.Stat(partLocation); err == nil {
+		validatorData, err := os.ReadFile(validatorLocation)
+		if err == nil && len(validatorData) > 0 {
+			offset = partStat.Size()
+			validator = string(validatorData)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed creating file at: %s: %w", location, err)
+		return 0, fmt.Errorf("failed to create request: %s: %w", url, err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
 	}
-	defer out.Close()
 
-	resp, err := http.Get(url)
+	resp, err := utils.HTTPClient().Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch: %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, err := parseContentRangeStart(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return 0, err
+		}
+		if start != offset {
+			return 0, fmt.Errorf("server resumed at byte %d instead of %d on url: %s", start, offset, url)
+		}
+	case http.StatusOK:
+		// The server does not support ranges, or the file changed since the partial download
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is invalid, the next attempt starts from scratch
+		_ = os.Remove(partLocation)
+		_ = os.Remove(validatorLocation)
 		return 0, fmt.Errorf("bad status: %s on url: %s", resp.Status, url)
+	default:
+		return 0, fmt.Errorf("bad status: %s on url: %s", resp.Status, url)
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	if expectedSize > 0 && total >= 0 && total != expectedSize {
+		return 0, fmt.Errorf("unexpected size for %s: expected %d bytes, server sent %d", url, expectedSize, total)
+	}
+
+	if updates != nil {
+		updates <- utils.GenericProgress{Completed: offset, Total: total}
+	}
+
+	hasher := sha256.New()
+
+	var out *os.File
+	if offset > 0 {
+		out, err = os.OpenFile(partLocation, os.O_RDWR, 0o666)
+		if err != nil {
+			return 0, fmt.Errorf("failed opening partial file at: %s: %w", partLocation, err)
+		}
+
+		// Include the already downloaded part in the hash
+		if _, err := io.CopyN(hasher, out, offset); err != nil {
+			out.Close()
+			return 0, fmt.Errorf("failed to hash partial file: %s: %w", partLocation, err)
+		}
+	} else {
+		out, err = os.Create(partLocation)
+		if err != nil {
+			return 0, fmt.Errorf("failed creating file at: %s: %w", partLocation, err)
+		}
+	}
+	defer out.Close()
+
+	if newValidator := responseValidator(resp); newValidator != "" {
+		if err := os.WriteFile(validatorLocation, []byte(newValidator), 0o666); err != nil {
+			return 0, fmt.Errorf("failed writing validator at: %s: %w", validatorLocation, err)
+		}
+	} else {
+		_ = os.Remove(validatorLocation)
 	}
 
 	progresser := &utils.Progresser{
-		Total:   resp.ContentLength,
+		Total:   total,
+		Running: offset,
 		Updates: updates,
 	}
 
-	_, err = io.Copy(io.MultiWriter(out, progresser), resp.Body)
+	written, err := io.Copy(io.MultiWriter(out, progresser, hasher), resp.Body)
+	_ = out.Sync()
 	if err != nil {
 		return 0, fmt.Errorf("failed writing file to disk: %w", err)
 	}
 
-	_ = out.Sync()
+	size := offset + written
+	if total >= 0 && size != total {
+		return 0, fmt.Errorf("download of %s ended after %d of %d bytes", url, size, total)
+	}
+
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("failed closing file at: %s: %w", partLocation, err)
+	}
+
+	_ = os.Remove(validatorLocation)
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); hash != "" && actual != hash {
+		quarantinePath, err := quarantineFile(cacheKey, partLocation)
+		if err != nil {
+			return 0, err
+		}
+
+		return 0, &HashMismatchError{
+			CacheKey:       cacheKey,
+			URL:            url,
+			Expected:       hash,
+			Actual:         actual,
+			QuarantinePath: quarantinePath,
+		}
+	}
+
+	if err := os.Rename(partLocation, location); err != nil {
+		return 0, fmt.Errorf("failed moving downloaded file to: %s: %w", location, err)
+	}
 
 	if updates != nil {
-		updates <- utils.GenericProgress{Completed: resp.ContentLength, Total: resp.ContentLength}
+		updates <- utils.GenericProgress{Completed: size, Total: size}
 	}
 
 	_, err = addFileToCache(cacheKey)
@@ -210,7 +370,57 @@ func downloadInternal(cacheKey string, location string, hash string, url string,
 		return 0, fmt.Errorf("failed to add file to cache: %w", err)
 	}
 
-	return resp.ContentLength, nil
+	return size, nil
+}
+
+// responseValidator returns the value to use for If-Range when resuming the response
+func responseValidator(resp *http.Response) string {
+	// Weak ETags cannot be used with If-Range
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRangeStart returns the first byte position of a "bytes start-end/size" Content-Range header
+func parseContentRangeStart(contentRange string) (int64, error) {
+	rangeSpec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, fmt.Errorf("invalid Content-Range: %s", contentRange)
+	}
+
+	start, _, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid Content-Range: %s", contentRange)
+	}
+
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range: %s: %w", contentRange, err)
+	}
+
+	return offset, nil
+}
+
+// partialLocation is where a download is written until it is complete and verified.
+// Partial downloads are kept out of the download cache, which only contains mod archives.
+func partialLocation(cacheKey string) string {
+	return filepath.Join(viper.GetString("cache-dir"), "partialDownloads", cacheKey+".part")
+}
+
+// quarantineFile moves a file which failed verification out of the download cache
+func quarantineFile(cacheKey string, location string) (string, error) {
+	quarantineDir := filepath.Join(viper.GetString("cache-dir"), "quarantine")
+	if err := os.MkdirAll(quarantineDir, 0o777); err != nil {
+		return "", fmt.Errorf("failed creating quarantine directory: %w", err)
+	}
+
+	quarantinePath := filepath.Join(quarantineDir, fmt.Sprintf("%s.%d", cacheKey, time.Now().UnixNano()))
+	if err := os.Rename(location, quarantinePath); err != nil {
+		return "", fmt.Errorf("failed to quarantine file: %s: %w", location, err)
+	}
+
+	return quarantinePath, nil
 }
 
 func compareHash(hash string, location string) (bool, error) {
@@ -231,3 +441,43 @@ func compareHash(hash string, location string) (bool, error) {
 
 	return hash == existingHash, nil
 }
+
+// OpenLocalArchive opens an archive of a static repository on this machine,
+// after checking it against the hash of the repository index
+func OpenLocalArchive(location string, hash string) (*os.File, int64, error) {
+	f, err := os.Open(location)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file: %s: %w", location, err)
+	}
+
+	if hash != "" {
+		actual, err := utils.SHA256Data(f)
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("could not compute hash for file: %s: %w", location, err)
+		}
+
+		if actual != hash {
+			f.Close()
+			return nil, 0, &HashMismatchError{
+				CacheKey: filepath.Base(location),
+				URL:      "file://" + location,
+				Expected: hash,
+				Actual:   actual,
+			}
+		}
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("failed to seek file: %s: %w", location, err)
+		}
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("failed to stat file: %s: %w", location, err)
+	}
+
+	return f, stat.Size(), nil
+}
@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+)
+
+func init() {
+	cfg.SetDefaults()
+	retryDelay = time.Millisecond
+}
+
+// useTempCacheDir points the cache at an empty directory for the test,
+// so files cached by previous runs do not change the requests made
+func useTempCacheDir(t *testing.T) {
+	t.Helper()
+
+	cacheDir := viper.GetString("cache-dir")
+	viper.Set("cache-dir", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache-dir", cacheDir)
+	})
+}
+
+func testModZip(t *testing.T, modReference string, padding int) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+
+	w, err := writer.Create(modReference + ".uplugin")
+	testza.AssertNoError(t, err)
+	_, err = w.Write([]byte(`{"SemVersion": "1.0.0"}`))
+	testza.AssertNoError(t, err)
+
+	w, err = writer.CreateHeader(&zip.FileHeader{Name: "padding", Method: zip.Store})
+	testza.AssertNoError(t, err)
+	_, err = w.Write(bytes.Repeat([]byte{0}, padding))
+	testza.AssertNoError(t, err)
+
+	testza.AssertNoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestDownloadHashMismatch(t *testing.T) {
+	useTempCacheDir(t)
+
+	body := []byte("truncated")
+	expected := sha256.Sum256([]byte("the real file"))
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	_, _, err := DownloadOrCache(context.Background(), "HashMismatch_1.0.0_Windows.zip", hex.EncodeToString(expected[:]), 0, server.URL, nil, nil)
+
+	var mismatch *HashMismatchError
+	testza.AssertTrue(t, errors.As(err, &mismatch))
+
+	// Downloaded again once, in case the first download was corrupted, but not until the attempts run out
+	testza.AssertEqual(t, int32(2), requests.Load())
+
+	actual := sha256.Sum256(body)
+	testza.AssertEqual(t, hex.EncodeToString(actual[:]), mismatch.Actual)
+
+	_, err = os.Stat(filepath.Join(viper.GetString("cache-dir"), "downloadCache", "HashMismatch_1.0.0_Windows.zip"))
+	testza.AssertTrue(t, os.IsNotExist(err))
+
+	quarantined, err := os.ReadFile(mismatch.QuarantinePath)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, body, quarantined)
+}
+
+func TestDownloadResume(t *testing.T) {
+	useTempCacheDir(t)
+
+	_, err := LoadCacheMods()
+	testza.AssertNoError(t, err)
+
+	body := testModZip(t, "Resume", 100000)
+	hash := sha256.Sum256(body)
+
+	var requests atomic.Int32
+	var resumedRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Drop the connection half-way through the first response
+			w.Header().Set("ETag", `"resume-test"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			_, _ = w.Write(body[:len(body)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		resumedRange = r.Header.Get("Range")
+		w.Header().Set("ETag", `"resume-test"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	}))
+	defer server.Close()
+
+	f, size, err := DownloadOrCache(context.Background(), "Resume_1.0.0_Windows.zip", hex.EncodeToString(hash[:]), int64(len(body)), server.URL, nil, nil)
+	testza.AssertNoError(t, err)
+	defer f.Close()
+
+	testza.AssertEqual(t, int64(len(body)), size)
+	testza.AssertEqual(t, int32(2), requests.Load())
+	testza.AssertTrue(t, strings.HasPrefix(resumedRange, "bytes="))
+	testza.AssertNotEqual(t, "bytes=0-", resumedRange)
+
+	downloaded, err := io.ReadAll(f)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, body, downloaded)
+
+	_, err = os.Stat(partialLocation("Resume_1.0.0_Windows.zip"))
+	testza.AssertTrue(t, os.IsNotExist(err))
+}
+
+func TestDownloadRestartWithoutRangeSupport(t *testing.T) {
+	useTempCacheDir(t)
+
+	_, err := LoadCacheMods()
+	testza.AssertNoError(t, err)
+
+	body := testModZip(t, "NoRange", 10)
+	hash := sha256.Sum256(body)
+
+	var requests atomic.Int32
+	partLocation := partialLocation("NoRange_1.0.0_Windows.zip")
+	testza.AssertNoError(t, os.MkdirAll(filepath.Dir(partLocation), 0o777))
+	testza.AssertNoError(t, os.WriteFile(partLocation, []byte("stale"), 0o666))
+	testza.AssertNoError(t, os.WriteFile(partLocation+".validator", []byte(`"old"`), 0o666))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	f, _, err := DownloadOrCache(context.Background(), "NoRange_1.0.0_Windows.zip", hex.EncodeToString(hash[:]), int64(len(body)), server.URL, nil, nil)
+	testza.AssertNoError(t, err)
+	defer f.Close()
+
+	testza.AssertEqual(t, int32(1), requests.Load())
+
+	downloaded, err := io.ReadAll(f)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, body, downloaded)
+}
+
+func TestDownloadSizeMismatch(t *testing.T) {
+	useTempCacheDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("too short"))
+	}))
+	defer server.Close()
+
+	_, _, err := DownloadOrCache(context.Background(), "SizeMismatch_1.0.0_Windows.zip", "", 1000, server.URL, nil, nil)
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, err.Error(), "unexpected size")
+}
+
+func TestDownloadCancel(t *testing.T) {
+	useTempCacheDir(t)
+
+	body := testModZip(t, "Cancel", 100000)
+	hash := sha256.Sum256(body)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"cancel-test"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body[:len(body)/2])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	partLocation := partialLocation("Cancel_1.0.0_Windows.zip")
+
+	// Cancel once the first half has been written to disk
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			if stat, err := os.Stat(partLocation); err == nil && stat.Size() >= int64(len(body)/2) {
+				cancel()
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	_, _, err := DownloadOrCache(ctx, "Cancel_1.0.0_Windows.zip", hex.EncodeToString(hash[:]), int64(len(body)), server.URL, nil, nil)
+	testza.AssertTrue(t, errors.Is(err, context.Canceled), err)
+	testza.AssertEqual(t, int32(1), requests.Load())
+
+	// The partial download is kept to be resumed later
+	_, err = os.Stat(partLocation)
+	testza.AssertNoError(t, err)
+
+	// Outside of the download cache, where it would be read as a mod archive
+	entries, err := os.ReadDir(filepath.Join(viper.GetString("cache-dir"), "downloadCache"))
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, entries, 0)
+}
+
+func TestOpenLocalArchive(t *testing.T) {
+	body := testModZip(t, "Static", 100)
+	hash := sha256.Sum256(body)
+
+	location := filepath.Join(t.TempDir(), "Static.smod")
+	testza.AssertNoError(t, os.WriteFile(location, body, 0o600))
+
+	f, size, err := OpenLocalArchive(location, hex.EncodeToString(hash[:]))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, int64(len(body)), size)
+
+	opened, err := io.ReadAll(f)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, body, opened)
+	testza.AssertNoError(t, f.Close())
+
+	expected := sha256.Sum256([]byte("the real file"))
+	_, _, err = OpenLocalArchive(location, hex.EncodeToString(expected[:]))
+
+	var mismatch *HashMismatchError
+	testza.AssertTrue(t, errors.As(err, &mismatch))
+	testza.AssertEqual(t, hex.EncodeToString(hash[:]), mismatch.Actual)
+
+	// Archives of the repository are not quarantined
+	_, err = os.Stat(location)
+	testza.AssertNoError(t, err)
+}
@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// localSourceTargets are the directories a local source can use to provide a separate build per target
+var localSourceTargets = []string{"Windows", "WindowsServer", "LinuxServer"}
+
+// LocalSource is a mod archive or build directory on this machine
+// that is installed instead of the versions available in the repository
+type LocalSource struct {
+	UPlugin      UPlugin
+	Path         string
+	ModReference string
+	Hash         string
+
+	// Targets contains the targets the source has separate builds for.
+	// Empty if the same build is used for all targets.
+	Targets []string
+}
+
+// ReadLocalSource reads the mod reference, .uplugin and content hash of a .smod/.zip archive or a directory
+func ReadLocalSource(sourcePath string) (*LocalSource, error) {
+	absolutePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve absolute path of: %s: %w", sourcePath, err)
+	}
+
+	fsys, closer, err := openLocalSourceFS(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	targets := make([]string, 0)
+	for _, target := range localSourceTargets {
+		matches, err := fs.Glob(fsys, path.Join(target, "*.uplugin"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for uplugin file: %w", err)
+		}
+		if len(matches) > 0 {
+			targets = append(targets, target)
+		}
+	}
+
+	root := "."
+	if len(targets) > 0 {
+		root = targets[0]
+	}
+
+	matches, err := fs.Glob(fsys, path.Join(root, "*.uplugin"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for uplugin file: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, errors.New("no uplugin file found in " + absolutePath)
+	}
+
+	data, err := fs.ReadFile(fsys, matches[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uplugin file: %w", err)
+	}
+
+	var uplugin UPlugin
+	if err := json.Unmarshal(data, &uplugin); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal uplugin file: %w", err)
+	}
+
+	if uplugin.SemVersion == "" {
+		return nil, errors.New("uplugin file does not specify a SemVersion")
+	}
+
+	hash, err := hashFS(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalSource{
+		Path:         absolutePath,
+		ModReference: strings.TrimSuffix(path.Base(matches[0]), ".uplugin"),
+		UPlugin:      uplugin,
+		Hash:         hash,
+		Targets:      targets,
+	}, nil
+}
+
+// OpenLocalSource returns a zip of the build of the local source for the provided target
+//
+// Archives without separate target builds are returned as-is,
+// anything else is packed into the cache directory first
+func OpenLocalSource(sourcePath string, target string) (*os.File, int64, error) {
+	stat, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat local source: %w", err)
+	}
+
+	fsys, closer, err := openLocalSourceFS(sourcePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closer.Close()
+
+	targetStat, err := fs.Stat(fsys, target)
+	hasTargetBuild := err == nil && targetStat.IsDir()
+
+	if !stat.IsDir() && !hasTargetBuild {
+		f, err := os.Open(sourcePath)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open file: %s: %w", sourcePath, err)
+		}
+		return f, stat.Size(), nil
+	}
+
+	if hasTargetBuild {
+		fsys, err = fs.Sub(fsys, target)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open %s build: %w", target, err)
+		}
+	}
+
+	// Packs are named after their content, so sources with the same name do not overwrite each other,
+	// and unchanged sources are not packed again
+	hash, err := hashFS(fsys)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	localSourcesDir := filepath.Join(viper.GetString("cache-dir"), "localSources")
+	if err := os.MkdirAll(localSourcesDir, 0o777); err != nil {
+		return nil, 0, fmt.Errorf("failed creating local sources cache: %w", err)
+	}
+
+	packedPath := filepath.Join(localSourcesDir, hash+".zip")
+	if _, err := os.Stat(packedPath); err != nil {
+		if err := packLocalSource(fsys, packedPath); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	packed, err := os.Open(packedPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open packed local source: %w", err)
+	}
+
+	packedStat, err := packed.Stat()
+	if err != nil {
+		packed.Close()
+		return nil, 0, fmt.Errorf("failed to stat packed local source: %w", err)
+	}
+
+	return packed, packedStat.Size(), nil
+}
+
+// packLocalSource packs the source next to the destination first, so an interrupted pack is never used
+func packLocalSource(fsys fs.FS, packedPath string) error {
+	partial, err := os.CreateTemp(filepath.Dir(packedPath), filepath.Base(packedPath)+".*.partial")
+	if err != nil {
+		return fmt.Errorf("failed creating packed local source: %w", err)
+	}
+	defer os.Remove(partial.Name())
+
+	if err := packFS(fsys, partial); err != nil {
+		partial.Close()
+		return err
+	}
+
+	if err := partial.Close(); err != nil {
+		return fmt.Errorf("failed writing packed local source: %w", err)
+	}
+
+	if err := os.Rename(partial.Name(), packedPath); err != nil {
+		return fmt.Errorf("failed moving packed local source: %w", err)
+	}
+
+	return nil
+}
+
+func openLocalSourceFS(sourcePath string) (fs.FS, io.Closer, error) {
+	stat, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat local source: %w", err)
+	}
+
+	if stat.IsDir() {
+		return os.DirFS(sourcePath), io.NopCloser(nil), nil
+	}
+
+	reader, err := zip.OpenReader(sourcePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read zip: %w", err)
+	}
+
+	return reader, reader, nil
+}
+
+// hashFS hashes the paths and contents of all files, in lexical order
+func hashFS(fsys fs.FS) (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		defer f.Close()
+
+		_, _ = io.WriteString(h, p+"\x00")
+		if _, err := io.Copy(h, f); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash local source: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func packFS(fsys fs.FS, out io.Writer) error {
+	writer := zip.NewWriter(out)
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		defer f.Close()
+
+		w, err := writer.Create(p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if _, err := io.Copy(w, f); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pack local source: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish packing local source: %w", err)
+	}
+
+	return nil
+}
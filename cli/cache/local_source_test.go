@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestOpenLocalSourceSameName(t *testing.T) {
+	useTempCacheDir(t)
+
+	// Two checkouts of the same mod, with different builds
+	sources := []string{
+		filepath.Join(t.TempDir(), "AreaActions"),
+		filepath.Join(t.TempDir(), "AreaActions"),
+	}
+
+	for i, source := range sources {
+		testza.AssertNoError(t, os.MkdirAll(source, 0o755))
+		testza.AssertNoError(t, os.WriteFile(filepath.Join(source, "AreaActions.uplugin"), []byte(`{"SemVersion": "1.0.0"}`), 0o600))
+		testza.AssertNoError(t, os.WriteFile(filepath.Join(source, "build.txt"), []byte{byte('a' + i)}, 0o600))
+	}
+
+	var packs []string
+	for _, source := range sources {
+		f, size, err := OpenLocalSource(source, "Windows")
+		testza.AssertNoError(t, err)
+
+		reader, err := zip.NewReader(f, size)
+		testza.AssertNoError(t, err)
+		testza.AssertLen(t, reader.File, 2)
+
+		packs = append(packs, f.Name())
+		testza.AssertNoError(t, f.Close())
+	}
+
+	testza.AssertNotEqual(t, packs[0], packs[1])
+
+	for i, pack := range packs {
+		reader, err := zip.OpenReader(pack)
+		testza.AssertNoError(t, err)
+
+		build, err := reader.Open("build.txt")
+		testza.AssertNoError(t, err)
+
+		content, err := io.ReadAll(build)
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, []byte{byte('a' + i)}, content)
+
+		testza.AssertNoError(t, reader.Close())
+	}
+
+	// Packing an unchanged source again reuses its pack
+	f, _, err := OpenLocalSource(sources[0], "Windows")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, packs[0], f.Name())
+	testza.AssertNoError(t, f.Close())
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+)
+
+// ClientMod is a mod installed on a server, considered for the profile of its players
+type ClientMod struct {
+	Reference string
+	Version   string
+	// Reason explains why the mod was not added to the profile
+	Reason string
+	// Added is true if players need the mod to join the server
+	Added bool
+}
+
+// AddClientProfile creates a profile for the players of a server installation, with the mods of its lockfile which
+// are required on the client side, pinned to the installed versions.
+// Mods are required on the client side when they are required on remote or when a required mod depends on them.
+func (p *Profiles) AddClientProfile(ctx context.Context, global *GlobalContext, installation *Installation, name string, target resolver.TargetName) (*Profile, []*ClientMod, error) {
+	lockfile, err := installation.LockFile(ctx, global)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if lockfile == nil {
+		return nil, nil, errors.New("installation has no lockfile, apply it first")
+	}
+
+	if p.GetProfile(name) != nil {
+		return nil, nil, fmt.Errorf("profile with name %s already exists", name)
+	}
+
+	mods, err := clientMods(ctx, global, lockfile, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile, err := p.AddProfile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile.RequiredTargets = []resolver.TargetName{target}
+
+	for _, mod := range mods {
+		if !mod.Added {
+			continue
+		}
+
+		if err := profile.AddMod(mod.Reference, mod.Version); err != nil {
+			_ = p.DeleteProfile(name)
+			return nil, nil, fmt.Errorf("failed to add %s: %w", mod.Reference, err)
+		}
+	}
+
+	return profile, mods, nil
+}
+
+func clientMods(ctx context.Context, global *GlobalContext, lockfile *resolver.LockFile, target resolver.TargetName) ([]*ClientMod, error) {
+	mods := make(map[string]*ClientMod, len(lockfile.Mods))
+	available := make(map[string]bool, len(lockfile.Mods))
+
+	for reference, lockedMod := range lockfile.Mods {
+		mod := &ClientMod{
+			Reference: reference,
+			Version:   lockedMod.Version,
+		}
+		mods[reference] = mod
+
+		versions, err := global.Provider.ModVersionsWithDependencies(ctx, reference)
+		if err != nil && !errors.Is(err, provider.ErrModNotFound) {
+			return nil, fmt.Errorf("failed to fetch mod %s: %w", reference, err)
+		}
+
+		if len(versions) == 0 {
+			slog.Info("mod not found in the repository", slog.String("mod_reference", reference), slog.Any("err", err))
+			mod.Reason = "not found in the repository"
+			continue
+		}
+
+		index := slices.IndexFunc(versions, func(version resolver.ModVersion) bool {
+			return version.Version == lockedMod.Version
+		})
+		if index == -1 {
+			mod.Reason = "version " + lockedMod.Version + " not found in the repository"
+			continue
+		}
+
+		hasTarget := slices.ContainsFunc(versions[index].Targets, func(t resolver.Target) bool {
+			return t.TargetName == target
+		})
+		if !hasTarget {
+			mod.Reason = "not available for " + string(target)
+			continue
+		}
+
+		available[reference] = true
+
+		if !versions[index].RequiredOnRemote {
+			mod.Reason = "not required on clients"
+			continue
+		}
+
+		mod.Added = true
+	}
+
+	// Dependencies of the required mods are needed by the clients too, at the version installed on the server
+	var addDependencies func(reference string)
+	addDependencies = func(reference string) {
+		for dependency := range lockfile.Mods[reference].Dependencies {
+			mod, ok := mods[dependency]
+			if !ok || mod.Added || !available[dependency] {
+				continue
+			}
+
+			mod.Added = true
+			mod.Reason = ""
+			addDependencies(dependency)
+		}
+	}
+
+	for reference, mod := range mods {
+		if mod.Added {
+			addDependencies(reference)
+		}
+	}
+
+	result := make([]*ClientMod, 0, len(mods))
+	for _, mod := range mods {
+		result = append(result, mod)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Reference < result[b].Reference
+	})
+
+	return result, nil
+}
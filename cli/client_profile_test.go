@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+)
+
+func TestAddClientProfile(t *testing.T) {
+	ctx, installation, _ := newMemoryInstallation(t, "ServerTest")
+
+	_, _, err := ctx.Profiles.AddClientProfile(context.Background(), ctx, installation, "Players", resolver.TargetName("Windows"))
+	testza.AssertNotNil(t, err)
+
+	profile := ctx.Profiles.GetProfile("ServerTest")
+	testza.AssertNoError(t, profile.AddMod("ChatCommands", ">=1.0.0"))
+	testza.AssertNoError(t, profile.AddMod("ServerTweaks", ">=2.0.0"))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	players, mods, err := ctx.Profiles.AddClientProfile(context.Background(), ctx, installation, "Players", resolver.TargetName("Windows"))
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, mods, 4)
+	testza.AssertEqual(t, []resolver.TargetName{"Windows"}, players.RequiredTargets)
+	testza.AssertEqual(t, "1.0.0", players.Mods["AreaActions"].Version)
+	testza.AssertEqual(t, "3.6.0", players.Mods["SML"].Version)
+	testza.AssertFalse(t, players.HasMod("ChatCommands"))
+	testza.AssertFalse(t, players.HasMod("ServerTweaks"))
+
+	byReference := make(map[string]*ClientMod)
+	for _, mod := range mods {
+		byReference[mod.Reference] = mod
+	}
+
+	testza.AssertEqual(t, "not required on clients", byReference["ChatCommands"].Reason)
+	testza.AssertEqual(t, "not available for Windows", byReference["ServerTweaks"].Reason)
+
+	_, _, err = ctx.Profiles.AddClientProfile(context.Background(), ctx, installation, "Players", resolver.TargetName("Windows"))
+	testza.AssertNotNil(t, err)
+
+	// Only mods missing from the repository are skipped, other errors stop the creation of the profile
+	apiBase := viper.GetString("api-base")
+	viper.Set("api-base", "http://127.0.0.1:0")
+	_, _, err = ctx.Profiles.AddClientProfile(context.Background(), ctx, installation, "Unreachable", resolver.TargetName("Windows"))
+	viper.Set("api-base", apiBase)
+	testza.AssertNotNil(t, err)
+	testza.AssertNil(t, ctx.Profiles.GetProfile("Unreachable"))
+
+	// Players import the exported profile
+	exportPath := filepath.Join(t.TempDir(), "players.json")
+	testza.AssertNoError(t, ctx.Profiles.ExportProfile("Players", exportPath))
+
+	_, err = ctx.Profiles.ImportProfile(exportPath, "")
+	testza.AssertNotNil(t, err)
+
+	imported, err := ctx.Profiles.ImportProfile(exportPath, "Imported")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Imported", imported.Name)
+	testza.AssertEqual(t, players.Mods, imported.Mods)
+	testza.AssertEqual(t, players.RequiredTargets, imported.RequiredTargets)
+
+	lockfile, err := imported.Resolve(ctx.Provider, nil, 365306)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, lockfile.Mods, 2)
+}
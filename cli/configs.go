@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// ErrConfigChanged is returned when applying would overwrite a config file which was edited on the installation
+var ErrConfigChanged = errors.New("config file was changed on the installation")
+
+// configsLockFile records the hashes of the deployed config files, next to the lockfile
+const configsLockFile = "ficsit-configs-lock.json"
+
+// defaultConfigDirectory is where mods store their configuration, pulled unless other paths are given
+var defaultConfigDirectory = path.Join("FactoryGame", "Configs")
+
+// ProfileConfig is a config file deployed to the installations of a profile.
+// Templates are rendered with text/template, with the variables of the installation.
+type ProfileConfig struct {
+	Content  string `json:"content"`
+	Template bool   `json:"template,omitempty"`
+}
+
+type configsLock struct {
+	Files map[string]string `json:"files"`
+}
+
+// cleanConfigPath returns the config path relative to the installation, with forward slashes.
+// Backslashes are separators too, as profiles are shared between Windows and Linux installations.
+func cleanConfigPath(configPath string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(configPath, `\`, "/"))
+	if path.IsAbs(cleaned) || filepath.VolumeName(configPath) != "" || strings.Contains(cleaned, ":") || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("config path must be relative to the installation: %s", configPath)
+	}
+
+	return cleaned, nil
+}
+
+// SetConfig adds or replaces a config file of the profile, at the path relative to the installation
+func (p *Profile) SetConfig(configPath string, content string, isTemplate bool) error {
+	cleaned, err := cleanConfigPath(configPath)
+	if err != nil {
+		return err
+	}
+
+	if isTemplate {
+		if _, err := template.New(cleaned).Parse(content); err != nil {
+			return fmt.Errorf("invalid config template: %w", err)
+		}
+	}
+
+	if p.Configs == nil {
+		p.Configs = make(map[string]ProfileConfig)
+	}
+
+	p.Configs[cleaned] = ProfileConfig{
+		Content:  content,
+		Template: isTemplate,
+	}
+
+	return nil
+}
+
+// RemoveConfig removes a config file from the profile. The file is kept on the installations.
+func (p *Profile) RemoveConfig(configPath string) error {
+	cleaned, err := cleanConfigPath(configPath)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.Configs[cleaned]; !ok {
+		return fmt.Errorf("profile has no config %s", cleaned)
+	}
+
+	delete(p.Configs, cleaned)
+
+	if len(p.Configs) == 0 {
+		p.Configs = nil
+	}
+
+	return nil
+}
+
+// SetVariable sets a variable used by the config templates, or removes it if the value is empty
+func (i *Installation) SetVariable(name string, value string) {
+	if value == "" {
+		delete(i.Variables, name)
+		if len(i.Variables) == 0 {
+			i.Variables = nil
+		}
+		return
+	}
+
+	if i.Variables == nil {
+		i.Variables = make(map[string]string)
+	}
+
+	i.Variables[name] = value
+}
+
+// renderConfigs returns the contents of the config files of the profile for the installation
+func (i *Installation) renderConfigs(profile *Profile) (map[string][]byte, error) {
+	configs := make(map[string][]byte, len(profile.Configs))
+
+	variables := i.Variables
+	if variables == nil {
+		variables = make(map[string]string)
+	}
+
+	for configPath, config := range profile.Configs {
+		if !config.Template {
+			configs[configPath] = []byte(config.Content)
+			continue
+		}
+
+		tmpl, err := template.New(configPath).Option("missingkey=error").Parse(config.Content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config template %s: %w", configPath, err)
+		}
+
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, variables); err != nil {
+			return nil, fmt.Errorf("failed to render config %s: %w", configPath, err)
+		}
+
+		configs[configPath] = rendered.Bytes()
+	}
+
+	return configs, nil
+}
+
+func configHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func (i *Installation) configsLockPath(platform *Platform) string {
+	return filepath.Join(i.BasePath(), platform.LockfilePath, configsLockFile)
+}
+
+func (i *Installation) readConfigsLock(ctx context.Context, d disk.Disk, platform *Platform) (*configsLock, error) {
+	lock := &configsLock{
+		Files: make(map[string]string),
+	}
+
+	exists, err := d.Exists(ctx, i.configsLockPath(platform))
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return lock, nil
+	}
+
+	data, err := d.Read(ctx, i.configsLockPath(platform))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading configs lockfile: %w", err)
+	}
+
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed parsing configs lockfile: %w", err)
+	}
+
+	if lock.Files == nil {
+		lock.Files = make(map[string]string)
+	}
+
+	return lock, nil
+}
+
+// prepareConfigs renders the config files of the profile, and checks that none of the files they replace was edited
+// on the installation since it was deployed, unless overwriting is allowed
+func (i *Installation) prepareConfigs(ctx context.Context, global *GlobalContext, platform *Platform, options InstallOptions) (map[string][]byte, error) {
+	profile := global.Profiles.Profiles[i.Profile]
+	if options.rollbackOf != 0 {
+		// Entries of vanilla applies do not have configs
+		profile = options.profile
+	}
+
+	if i.Vanilla || profile == nil || len(profile.Configs) == 0 {
+		return nil, nil
+	}
+
+	configs, err := i.renderConfigs(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.OverwriteConfigs {
+		return configs, nil
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := i.readConfigsLock(ctx, d, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	for configPath, content := range configs {
+		fullPath := filepath.Join(i.BasePath(), filepath.FromSlash(configPath))
+
+		exists, err := d.Exists(ctx, fullPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			continue
+		}
+
+		current, err := d.Read(ctx, fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading config %s: %w", configPath, err)
+		}
+
+		currentHash := configHash(current)
+		if currentHash == configHash(content) || currentHash == lock.Files[configPath] {
+			continue
+		}
+
+		return nil, fmt.Errorf("%w: %s, pull it into the profile or apply while overwriting configs", ErrConfigChanged, configPath)
+	}
+
+	return configs, nil
+}
+
+// deployConfigs writes the config files, and records their hashes to detect later edits
+func (i *Installation) deployConfigs(ctx context.Context, platform *Platform, configs map[string][]byte) error {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	lock, err := i.readConfigsLock(ctx, d, platform)
+	if err != nil {
+		return err
+	}
+
+	for configPath, content := range configs {
+		fullPath := filepath.Join(i.BasePath(), filepath.FromSlash(configPath))
+
+		slog.Info("deploying config", slog.String("path", i.Path), slog.String("config", configPath))
+
+		if err := d.MkDir(ctx, filepath.Dir(fullPath)); err != nil {
+			return fmt.Errorf("failed creating config directory: %w", err)
+		}
+
+		if err := d.Write(ctx, fullPath, content); err != nil {
+			return fmt.Errorf("failed writing config %s: %w", configPath, err)
+		}
+
+		lock.Files[configPath] = configHash(content)
+	}
+
+	lockJSON, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize configs lockfile: %w", err)
+	}
+
+	if err := d.Write(ctx, i.configsLockPath(platform), lockJSON); err != nil {
+		return fmt.Errorf("failed writing configs lockfile: %w", err)
+	}
+
+	return nil
+}
+
+// PullConfigs copies config files from the installation into its profile, and returns the pulled paths.
+// Without paths, all files in FactoryGame/Configs and the configs already in the profile are pulled,
+// except templates, which are only replaced when their path is given.
+func (i *Installation) PullConfigs(ctx context.Context, global *GlobalContext, paths []string) ([]string, error) {
+	profile := global.Profiles.GetProfile(i.Profile)
+	if profile == nil {
+		return nil, fmt.Errorf("profile with name %s does not exist", i.Profile)
+	}
+
+	d, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		paths, err = i.defaultConfigPaths(ctx, d, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pulled := make([]string, 0, len(paths))
+	for _, configPath := range paths {
+		cleaned, err := cleanConfigPath(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := d.Read(ctx, filepath.Join(i.BasePath(), filepath.FromSlash(cleaned)))
+		if err != nil {
+			return nil, fmt.Errorf("failed reading config %s: %w", cleaned, err)
+		}
+
+		if err := profile.SetConfig(cleaned, string(content), false); err != nil {
+			return nil, err
+		}
+
+		pulled = append(pulled, cleaned)
+	}
+
+	return pulled, nil
+}
+
+func (i *Installation) defaultConfigPaths(ctx context.Context, d disk.Disk, profile *Profile) ([]string, error) {
+	var paths []string
+
+	for configPath, config := range profile.Configs {
+		if config.Template {
+			slog.Info("skipping config template", slog.String("config", configPath))
+			continue
+		}
+
+		exists, err := d.Exists(ctx, filepath.Join(i.BasePath(), filepath.FromSlash(configPath)))
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			paths = append(paths, configPath)
+		}
+	}
+
+	exists, err := d.Exists(ctx, filepath.Join(i.BasePath(), filepath.FromSlash(defaultConfigDirectory)))
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		files, err := listFiles(ctx, d, filepath.Join(i.BasePath(), filepath.FromSlash(defaultConfigDirectory)), defaultConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if config, ok := profile.Configs[file]; ok && config.Template {
+				continue
+			}
+
+			if !slices.Contains(paths, file) {
+				paths = append(paths, file)
+			}
+		}
+	}
+
+	slices.Sort(paths)
+
+	return paths, nil
+}
+
+// listFiles returns the paths of the files in the directory and its subdirectories, joined to the prefix with forward slashes
+func listFiles(ctx context.Context, d disk.Disk, directory string, prefix string) ([]string, error) {
+	entries, err := d.ReadDir(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", directory, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			subFiles, err := listFiles(ctx, d, filepath.Join(directory, entry.Name()), path.Join(prefix, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, subFiles...)
+			continue
+		}
+
+		files = append(files, path.Join(prefix, entry.Name()))
+	}
+
+	return files, nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestProfileConfigs(t *testing.T) {
+	ctx, installation, d := newMemoryInstallation(t, "ConfigsTest")
+	profile := ctx.Profiles.Profiles["ConfigsTest"]
+
+	testza.AssertNotNil(t, profile.SetConfig("../outside.cfg", "", false))
+	testza.AssertNotNil(t, profile.SetConfig("FactoryGame/Configs/Broken.cfg", "{{ .port", true))
+
+	testza.AssertNoError(t, profile.SetConfig("FactoryGame/Configs/AreaActions.cfg", `{"range": 5}`, false))
+	testza.AssertNoError(t, profile.SetConfig(`FactoryGame\Saved\Config\LinuxServer\Game.ini`, "Port={{ .port }}", true))
+
+	// Templates fail without their variables
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNotNil(t, err)
+
+	installation.SetVariable("port", "7777")
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	data, err := d.Read(context.Background(), "/server/FactoryGame/Configs/AreaActions.cfg")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `{"range": 5}`, string(data))
+
+	data, err = d.Read(context.Background(), "/server/FactoryGame/Saved/Config/LinuxServer/Game.ini")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Port=7777", string(data))
+
+	// Local edits are not overwritten
+	testza.AssertNoError(t, d.Write(context.Background(), "/server/FactoryGame/Configs/AreaActions.cfg", []byte(`{"range": 10}`)))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertTrue(t, errors.Is(err, ErrConfigChanged))
+
+	// Until they are pulled into the profile
+	pulled, err := installation.PullConfigs(context.Background(), ctx, nil)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []string{"FactoryGame/Configs/AreaActions.cfg"}, pulled)
+	testza.AssertEqual(t, `{"range": 10}`, profile.Configs["FactoryGame/Configs/AreaActions.cfg"].Content)
+	testza.AssertTrue(t, profile.Configs["FactoryGame/Saved/Config/LinuxServer/Game.ini"].Template)
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	// Or overwritten explicitly
+	testza.AssertNoError(t, d.Write(context.Background(), "/server/FactoryGame/Saved/Config/LinuxServer/Game.ini", []byte("Port=1")))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertTrue(t, errors.Is(err, ErrConfigChanged))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{OverwriteConfigs: true}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	data, err = d.Read(context.Background(), "/server/FactoryGame/Saved/Config/LinuxServer/Game.ini")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Port=7777", string(data))
+
+	testza.AssertNoError(t, profile.RemoveConfig("FactoryGame/Configs/AreaActions.cfg"))
+	testza.AssertNotNil(t, profile.RemoveConfig("FactoryGame/Configs/AreaActions.cfg"))
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
@@ -29,7 +30,8 @@ func InitCLI(apiOnly bool) (*GlobalContext, error) {
 
 	apiClient := ficsit.InitAPI()
 
-	mixedProvider := provider.InitMixedProvider(provider.NewFicsitProvider(apiClient), provider.NewLocalProvider())
+	onlineProvider := provider.InitRepositoryProvider(viper.GetStringSlice("repositories"), provider.NewFicsitProvider(apiClient))
+	mixedProvider := provider.InitMixedProvider(onlineProvider, provider.NewLocalProvider())
 
 	if viper.GetBool("offline") {
 		mixedProvider.Offline = true
@@ -93,12 +95,12 @@ func (g *GlobalContext) ReInit() error {
 }
 
 // Wipe will remove any trace of ficsit anywhere
-func (g *GlobalContext) Wipe() error {
+func (g *GlobalContext) Wipe(ctx context.Context) error {
 	slog.Info("wiping global context")
 
 	// Wipe all installations
 	for _, installation := range g.Installations.Installations {
-		if err := installation.Wipe(); err != nil {
+		if err := installation.Wipe(ctx); err != nil {
 			return fmt.Errorf("failed wiping installation: %w", err)
 		}
 
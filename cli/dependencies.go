@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/mircearoata/pubgrub-go/pubgrub/semver"
+	"github.com/puzpuzpuz/xsync/v3"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+)
+
+type OptionalDependencyPolicy string
+
+const (
+	// OptionalDependenciesDefault inherits the policy of the profile.
+	// On the profile level it behaves like OptionalDependenciesSkip.
+	OptionalDependenciesDefault OptionalDependencyPolicy = ""
+
+	// OptionalDependenciesInstall installs optional dependencies alongside the mod
+	OptionalDependenciesInstall OptionalDependencyPolicy = "install"
+
+	// OptionalDependenciesSkip installs optional dependencies only if something else requires them
+	OptionalDependenciesSkip OptionalDependencyPolicy = "skip"
+)
+
+// ParseOptionalDependencyPolicy returns the policy matching the provided name
+func ParseOptionalDependencyPolicy(name string) (OptionalDependencyPolicy, error) {
+	switch OptionalDependencyPolicy(name) {
+	case OptionalDependenciesInstall, OptionalDependenciesSkip:
+		return OptionalDependencyPolicy(name), nil
+	case "default":
+		return OptionalDependenciesDefault, nil
+	}
+
+	return "", fmt.Errorf("unknown optional dependency policy: %s", name)
+}
+
+var _ resolver.Provider = (*profileProvider)(nil)
+
+// profileProvider applies the local sources, the optional dependency policy and the exclusions of a profile
+// to the mod versions returned by the wrapped provider
+type profileProvider struct {
+	resolver.Provider
+	profile     *Profile
+	local       *localVersions
+	gameVersion int
+
+	// unavailable keeps why optional dependencies cannot be installed, by mod and condition
+	unavailable *xsync.MapOf[string, string]
+}
+
+// localVersions keeps the versions of the local sources read during a resolve,
+// as reading a local source hashes all of its files and the resolver requests the versions of a mod many times
+type localVersions struct {
+	versions map[string]resolver.ModVersion
+	mu       sync.Mutex
+}
+
+func newProfileProvider(provider resolver.Provider, profile *Profile, gameVersion int) profileProvider {
+	return profileProvider{
+		Provider: provider,
+		profile:  profile,
+		local: &localVersions{
+			versions: make(map[string]resolver.ModVersion),
+		},
+		gameVersion: gameVersion,
+		unavailable: xsync.NewMapOf[string, string](),
+	}
+}
+
+func (p profileProvider) ModVersionsWithDependencies(ctx context.Context, modID string) ([]resolver.ModVersion, error) {
+	if p.profile.IsModExcluded(modID) {
+		return []resolver.ModVersion{}, nil
+	}
+
+	versions, err := p.modVersions(ctx, modID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.profile.optionalDependencyPolicyFor(modID) != OptionalDependenciesInstall {
+		return versions, nil
+	}
+
+	modVersions := make([]resolver.ModVersion, len(versions))
+	for i, version := range versions {
+		dependencies := make([]resolver.Dependency, len(version.Dependencies))
+		for j, dependency := range version.Dependencies {
+			if dependency.Optional && !p.profile.IsModExcluded(dependency.ModID) {
+				// Optional dependencies which cannot be installed are left optional,
+				// so that they do not prevent installing the mod
+				reason, err := p.unavailableReason(ctx, dependency)
+				if err != nil {
+					return nil, err
+				}
+
+				dependency.Optional = reason != ""
+			}
+			dependencies[j] = dependency
+		}
+
+		version.Dependencies = dependencies
+		modVersions[i] = version
+	}
+
+	return modVersions, nil
+}
+
+func (p profileProvider) modVersions(ctx context.Context, modID string) ([]resolver.ModVersion, error) {
+	sourcePath, ok := p.profile.localSource(modID)
+	if !ok {
+		return p.profile.modVersions(ctx, p.Provider, modID)
+	}
+
+	p.local.mu.Lock()
+	defer p.local.mu.Unlock()
+
+	version, ok := p.local.versions[sourcePath]
+	if !ok {
+		var err error
+		version, err = localModVersion(sourcePath, p.profile.Mods[modID].SourceTargets)
+		if err != nil {
+			return nil, err
+		}
+
+		p.local.versions[sourcePath] = version
+	}
+
+	return []resolver.ModVersion{version}, nil
+}
+
+func (p profileProvider) unavailableReason(ctx context.Context, dependency resolver.Dependency) (string, error) {
+	key := dependency.ModID + "@" + dependency.Condition
+	if reason, ok := p.unavailable.Load(key); ok {
+		return reason, nil
+	}
+
+	reason, err := p.profile.unavailableReason(ctx, p.Provider, dependency, p.gameVersion)
+	if err != nil {
+		return "", err
+	}
+
+	p.unavailable.Store(key, reason)
+
+	return reason, nil
+}
+
+// unavailableReason explains why no version of the dependency can be installed on its own,
+// or returns an empty string if one can.
+// Only the dependency itself is checked, not its own dependencies.
+func (p *Profile) unavailableReason(ctx context.Context, source resolver.Provider, dependency resolver.Dependency, gameVersion int) (string, error) {
+	versions, err := p.modVersions(ctx, source, dependency.ModID)
+	if err != nil && !errors.Is(err, provider.ErrModNotFound) {
+		return "", fmt.Errorf("failed to fetch mod %s: %w", dependency.ModID, err)
+	}
+
+	if len(versions) == 0 {
+		return "not found in any repository", nil
+	}
+
+	constraint, err := semver.NewConstraint(dependency.Condition)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse constraint %s: %w", dependency.Condition, err)
+	}
+
+	game, err := semver.NewVersion(strconv.Itoa(gameVersion))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse game version %d: %w", gameVersion, err)
+	}
+
+	matching, compatible := false, false
+	for _, modVersion := range versions {
+		v, err := semver.NewVersion(modVersion.Version)
+		if err != nil || !constraint.Contains(v) {
+			continue
+		}
+		matching = true
+
+		// Versions without a game version range do not depend on the game version
+		if modVersion.GameVersion != "" {
+			gameConstraint, err := semver.NewConstraint(modVersion.GameVersion)
+			if err != nil || !gameConstraint.Contains(game) {
+				continue
+			}
+		}
+		compatible = true
+
+		if _, usable := missingTargets(modVersion, p.RequiredTargets); usable {
+			return "", nil
+		}
+	}
+
+	switch {
+	case !matching:
+		return fmt.Sprintf("no version matches %s", dependency.Condition), nil
+	case !compatible:
+		return fmt.Sprintf("no version matching %s supports the game version", dependency.Condition), nil
+	default:
+		return fmt.Sprintf("no version matching %s provides the required targets", dependency.Condition), nil
+	}
+}
+
+type DependencyStatus string
+
+const (
+	DependencyStatusInstalled DependencyStatus = "installed"
+	DependencyStatusSkipped   DependencyStatus = "skipped"
+)
+
+// DependencyTreeNode is a mod of a resolved profile, along with the dependencies of its locked version
+type DependencyTreeNode struct {
+	ModReference string
+	Version      string
+	Condition    string
+	Status       DependencyStatus
+
+	// Reason explains why an optional dependency was installed or skipped
+	Reason       string
+	Optional     bool
+	Dependencies []*DependencyTreeNode
+}
+
+// DependencyTree builds the dependency tree of the enabled mods of the profile from a lockfile resolved for it
+// and the game version it was resolved for
+func (p *Profile) DependencyTree(ctx context.Context, provider resolver.Provider, lockFile *resolver.LockFile, gameVersion int) ([]*DependencyTreeNode, error) {
+	roots := make([]string, 0, len(p.Mods))
+	for modReference, mod := range p.Mods {
+		if mod.Enabled {
+			roots = append(roots, modReference)
+		}
+	}
+	sort.Strings(roots)
+
+	visited := make(map[string]bool)
+	nodes := make([]*DependencyTreeNode, 0, len(roots))
+	for _, modReference := range roots {
+		node := &DependencyTreeNode{
+			ModReference: modReference,
+			Condition:    p.Mods[modReference].Version,
+		}
+
+		if err := p.fillDependencyTree(ctx, provider, lockFile, gameVersion, "", node, visited); err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (p *Profile) fillDependencyTree(ctx context.Context, provider resolver.Provider, lockFile *resolver.LockFile, gameVersion int, parent string, node *DependencyTreeNode, visited map[string]bool) error {
+	locked, ok := lockFile.Mods[node.ModReference]
+	if !ok {
+		node.Status = DependencyStatusSkipped
+		switch {
+		case p.IsModExcluded(node.ModReference):
+			node.Reason = "excluded by profile"
+		case node.Optional && p.optionalDependencyPolicyFor(parent) == OptionalDependenciesInstall:
+			reason, err := p.unavailableReason(ctx, provider, resolver.Dependency{
+				ModID:     node.ModReference,
+				Condition: node.Condition,
+			}, gameVersion)
+			if err != nil {
+				return err
+			}
+			if reason == "" {
+				reason = "not part of the resolved mods"
+			}
+			node.Reason = "optional dependency cannot be installed: " + reason
+		case node.Optional:
+			node.Reason = "optional dependencies are not installed by the profile policy"
+		default:
+			node.Reason = "not part of the resolved mods"
+		}
+		return nil
+	}
+
+	node.Version = locked.Version
+	node.Status = DependencyStatusInstalled
+
+	if node.Optional {
+		switch {
+		case p.HasMod(node.ModReference):
+			node.Reason = "part of the profile"
+		case p.optionalDependencyPolicyFor(parent) == OptionalDependenciesInstall:
+			node.Reason = "optional dependencies are installed by the profile policy"
+		default:
+			node.Reason = "required by another mod"
+		}
+	}
+
+	// Only expand every mod once, in case multiple mods depend on it
+	if visited[node.ModReference] {
+		return nil
+	}
+	visited[node.ModReference] = true
+
+	versions, err := p.modVersions(ctx, provider, node.ModReference)
+	if err != nil {
+		return fmt.Errorf("failed to fetch mod %s: %w", node.ModReference, err)
+	}
+
+	idx := slices.IndexFunc(versions, func(version resolver.ModVersion) bool {
+		return version.Version == locked.Version
+	})
+	if idx == -1 {
+		return nil
+	}
+
+	dependencies := slices.Clone(versions[idx].Dependencies)
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].ModID < dependencies[j].ModID
+	})
+
+	for _, dependency := range dependencies {
+		child := &DependencyTreeNode{
+			ModReference: dependency.ModID,
+			Condition:    dependency.Condition,
+			Optional:     dependency.Optional,
+		}
+
+		if err := p.fillDependencyTree(ctx, provider, lockFile, gameVersion, node.ModReference, child, visited); err != nil {
+			return err
+		}
+
+		node.Dependencies = append(node.Dependencies, child)
+	}
+
+	return nil
+}
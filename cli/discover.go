@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Steam app IDs of the game and its dedicated server
+var steamAppIDs = []string{"526870", "1690800"}
+
+// DiscoveredInstallation is an installation found on this machine
+type DiscoveredInstallation struct {
+	Path     string
+	Source   string
+	Platform string
+	// Added is true if the installation has already been added
+	Added bool
+}
+
+// discoverySources are the places searched for installations
+type discoverySources struct {
+	// steam are roots of Steam or SteamCMD, containing a steamapps directory
+	steam []string
+	// epic are directories containing Epic Games Launcher manifests
+	epic []string
+	// legendary are installed.json files of Legendary and Heroic
+	legendary []string
+	// servers are directories which may be dedicated server installations themselves
+	servers []string
+}
+
+func defaultDiscoverySources() discoverySources {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		slog.Warn("failed to get home directory", slog.Any("err", err))
+	}
+
+	var sources discoverySources
+
+	if runtime.GOOS == "windows" {
+		for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+			if dir := os.Getenv(env); dir != "" {
+				sources.steam = append(sources.steam, filepath.Join(dir, "Steam"))
+			}
+		}
+
+		sources.steam = append(sources.steam, `C:\steamcmd`)
+
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			sources.epic = append(sources.epic, filepath.Join(dir, "Epic", "EpicGamesLauncher", "Data", "Manifests"))
+		}
+
+		return sources
+	}
+
+	if home != "" {
+		sources.steam = append(sources.steam,
+			filepath.Join(home, ".steam", "steam"),
+			filepath.Join(home, ".local", "share", "Steam"),
+			filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+			filepath.Join(home, ".steam", "steamcmd"),
+			filepath.Join(home, "Steam"),
+		)
+
+		sources.legendary = append(sources.legendary,
+			filepath.Join(home, ".config", "legendary", "installed.json"),
+			filepath.Join(home, ".config", "heroic", "legendaryConfig", "legendary", "installed.json"),
+		)
+
+		sources.servers = append(sources.servers,
+			filepath.Join(home, "SatisfactoryDedicatedServer"),
+			// LinuxGSM
+			filepath.Join(home, "serverfiles"),
+		)
+	}
+
+	// LinuxGSM and SteamCMD servers usually run as their own user
+	for _, pattern := range []string{"/home/*/serverfiles", "/home/*/Steam"} {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if filepath.Base(match) == "Steam" {
+				sources.steam = append(sources.steam, match)
+			} else {
+				sources.servers = append(sources.servers, match)
+			}
+		}
+	}
+
+	return sources
+}
+
+// Discover searches this machine for installations of the game and dedicated server.
+// Discovery is best effort, sources which cannot be read are skipped.
+func (i *Installations) Discover(ctx context.Context, global *GlobalContext) []*DiscoveredInstallation {
+	return i.discover(ctx, global, defaultDiscoverySources())
+}
+
+func (i *Installations) discover(ctx context.Context, global *GlobalContext, sources discoverySources) []*DiscoveredInstallation {
+	type candidate struct {
+		path   string
+		source string
+	}
+
+	var candidates []candidate
+
+	for _, root := range sources.steam {
+		for _, path := range steamInstallations(root) {
+			candidates = append(candidates, candidate{path: path, source: "steam"})
+		}
+	}
+
+	for _, dir := range sources.epic {
+		for _, path := range epicInstallations(dir) {
+			candidates = append(candidates, candidate{path: path, source: "epic"})
+		}
+	}
+
+	for _, file := range sources.legendary {
+		for _, path := range legendaryInstallations(file) {
+			candidates = append(candidates, candidate{path: path, source: "epic"})
+		}
+	}
+
+	for _, dir := range sources.servers {
+		candidates = append(candidates, candidate{path: dir, source: "server"})
+	}
+
+	seen := make(map[string]bool)
+
+	var discovered []*DiscoveredInstallation
+	for _, c := range candidates {
+		path, err := filepath.Abs(c.path)
+		if err != nil {
+			continue
+		}
+
+		// Steam roots are often symlinked to each other
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		} else {
+			continue
+		}
+
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		installation := &Installation{
+			Path:    path,
+			Profile: global.Profiles.SelectedProfile,
+		}
+
+		if err := installation.Validate(ctx, global); err != nil {
+			slog.Debug("skipping discovered path", slog.String("path", path), slog.Any("err", err))
+			continue
+		}
+
+		platform, err := installation.GetPlatform(ctx, global)
+		if err != nil {
+			slog.Debug("skipping discovered path", slog.String("path", path), slog.Any("err", err))
+			continue
+		}
+
+		// Installations may have been added through a symlink
+		added := false
+		for _, install := range i.Installations {
+			installPath := filepath.Clean(install.Path)
+			if resolved, err := filepath.EvalSymlinks(installPath); err == nil {
+				installPath = resolved
+			}
+
+			if installPath == path {
+				added = true
+				break
+			}
+		}
+
+		discovered = append(discovered, &DiscoveredInstallation{
+			Path:     path,
+			Source:   c.source,
+			Platform: platform.TargetName,
+			Added:    added,
+		})
+	}
+
+	return discovered
+}
+
+// steamInstallations returns the install directories of the game and server
+// in every library of a Steam root
+func steamInstallations(root string) []string {
+	libraries := []string{root}
+
+	for _, file := range []string{filepath.Join(root, "steamapps", "libraryfolders.vdf"), filepath.Join(root, "config", "libraryfolders.vdf")} {
+		folders, err := readVDF(file)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("failed to read steam library folders", slog.String("path", file), slog.Any("err", err))
+			}
+			continue
+		}
+
+		for _, library := range vdfSection(folders, "libraryfolders") {
+			switch library := library.(type) {
+			case map[string]any:
+				if path, ok := library["path"].(string); ok {
+					libraries = append(libraries, path)
+				}
+			case string:
+				// Before 2021, libraries were listed by their path only
+				if _, err := os.Stat(library); err == nil {
+					libraries = append(libraries, library)
+				}
+			}
+		}
+	}
+
+	var paths []string
+	for _, library := range libraries {
+		for _, appID := range steamAppIDs {
+			manifest, err := readVDF(filepath.Join(library, "steamapps", "appmanifest_"+appID+".acf"))
+			if err != nil {
+				continue
+			}
+
+			if installDir, ok := vdfSection(manifest, "appstate")["installdir"].(string); ok && installDir != "" {
+				paths = append(paths, filepath.Join(library, "steamapps", "common", installDir))
+			}
+		}
+	}
+
+	return paths
+}
+
+// epicInstallations returns the install locations of the Epic Games Launcher manifests in a directory
+func epicInstallations(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".item" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		var manifest struct {
+			InstallLocation string `json:"InstallLocation"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			slog.Warn("failed to parse epic manifest", slog.String("path", entry.Name()), slog.Any("err", err))
+			continue
+		}
+
+		if manifest.InstallLocation != "" {
+			paths = append(paths, manifest.InstallLocation)
+		}
+	}
+
+	return paths
+}
+
+// legendaryInstallations returns the install paths of the games in a Legendary installed.json file
+func legendaryInstallations(file string) []string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	var installed map[string]struct {
+		InstallPath string `json:"install_path"`
+	}
+	if err := json.Unmarshal(data, &installed); err != nil {
+		slog.Warn("failed to parse legendary installed games", slog.String("path", file), slog.Any("err", err))
+		return nil
+	}
+
+	var paths []string
+	for _, game := range installed {
+		if game.InstallPath != "" {
+			paths = append(paths, game.InstallPath)
+		}
+	}
+
+	return paths
+}
+
+// vdfSection returns a section of a parsed VDF file, or nil if it is missing
+func vdfSection(values map[string]any, key string) map[string]any {
+	section, _ := values[key].(map[string]any)
+	return section
+}
+
+// readVDF parses a Valve KeyValues (VDF/ACF) file.
+// Values are either strings or nested sections, and keys are lower-cased as Valve keys are case-insensitive.
+func readVDF(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	tokens, err := vdfTokens(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values, rest, err := parseVDFSection(tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to parse %s: unexpected %q", path, rest[0].value)
+	}
+
+	return values, nil
+}
+
+type vdfToken struct {
+	value  string
+	quoted bool
+}
+
+func vdfTokens(data string) ([]vdfToken, error) {
+	var tokens []vdfToken
+
+	runes := []rune(data)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '{' || r == '}':
+			tokens = append(tokens, vdfToken{value: string(r)})
+		case r == '"':
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						value.WriteRune('\n')
+					case 't':
+						value.WriteRune('\t')
+					default:
+						value.WriteRune(runes[i])
+					}
+					continue
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, vdfToken{value: value.String(), quoted: true})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '{' && runes[i] != '}' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, vdfToken{value: string(runes[start:i]), quoted: true})
+			i--
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseVDFSection parses key-value pairs until the end of the tokens or of the current section
+func parseVDFSection(tokens []vdfToken) (map[string]any, []vdfToken, error) {
+	values := make(map[string]any)
+
+	for len(tokens) > 0 {
+		key := tokens[0]
+		if !key.quoted {
+			if key.value == "}" {
+				return values, tokens, nil
+			}
+			return nil, nil, fmt.Errorf("unexpected %q", key.value)
+		}
+
+		if len(tokens) < 2 {
+			return nil, nil, fmt.Errorf("missing value of %q", key.value)
+		}
+
+		value := tokens[1]
+		tokens = tokens[2:]
+
+		switch {
+		case value.quoted:
+			values[strings.ToLower(key.value)] = value.value
+		case value.value == "{":
+			section, rest, err := parseVDFSection(tokens)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(rest) == 0 {
+				return nil, nil, fmt.Errorf("unterminated section %q", key.value)
+			}
+
+			values[strings.ToLower(key.value)] = section
+			tokens = rest[1:]
+		default:
+			return nil, nil, fmt.Errorf("unexpected %q", value.value)
+		}
+	}
+
+	return values, nil, nil
+}
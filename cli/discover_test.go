@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func writeFakeInstallation(t *testing.T, dir string, executable string, platform Platform) {
+	t.Helper()
+
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(platform.VersionPath)), 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(dir, executable), []byte{}, 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(dir, platform.VersionPath), []byte(`{"Changelist": 365306}`), 0o644))
+}
+
+func TestDiscoverInstallations(t *testing.T) {
+	ctx, err := InitCLI(false)
+	testza.AssertNoError(t, err)
+
+	err = ctx.Wipe(context.Background())
+	testza.AssertNoError(t, err)
+
+	err = ctx.ReInit()
+	testza.AssertNoError(t, err)
+
+	// Discovered paths have their symlinks resolved
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	testza.AssertNoError(t, err)
+
+	steam := filepath.Join(root, "Steam")
+	library := filepath.Join(root, "Library")
+
+	// The game in the main library, and the server in a second library
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(steam, "steamapps"), 0o755))
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(library, "steamapps"), 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(steam, "steamapps", "libraryfolders.vdf"), []byte(`"libraryfolders"
+{
+	"0"
+	{
+		"path"		"`+steam+`"
+		"apps"
+		{
+			"526870"		"1000"
+		}
+	}
+	// A library on another drive
+	"1"
+	{
+		"path"		"`+library+`"
+	}
+}`), 0o644))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(steam, "steamapps", "appmanifest_526870.acf"), []byte(`"AppState"
+{
+	"appid"		"526870"
+	"InstallDir"		"Satisfactory"
+}`), 0o644))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(library, "steamapps", "appmanifest_1690800.acf"), []byte(`"AppState"
+{
+	"appid"		"1690800"
+	"installdir"		"SatisfactoryDedicatedServer"
+}`), 0o644))
+	writeFakeInstallation(t, filepath.Join(steam, "steamapps", "common", "Satisfactory"), "FactoryGameSteam.exe", platforms[5])
+	writeFakeInstallation(t, filepath.Join(library, "steamapps", "common", "SatisfactoryDedicatedServer"), "FactoryServer.sh", platforms[0])
+
+	// An Epic installation, and a manifest of another game
+	epic := filepath.Join(root, "Manifests")
+	testza.AssertNoError(t, os.MkdirAll(epic, 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(epic, "crab.item"), []byte(`{"AppName": "CrabEA", "InstallLocation": "`+filepath.ToSlash(filepath.Join(root, "Epic", "Satisfactory"))+`"}`), 0o644))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(epic, "other.item"), []byte(`{"AppName": "Other", "InstallLocation": "`+filepath.ToSlash(filepath.Join(root, "Epic", "Other"))+`"}`), 0o644))
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(root, "Epic", "Other"), 0o755))
+	writeFakeInstallation(t, filepath.Join(root, "Epic", "Satisfactory"), "FactoryGameEGS.exe", platforms[6])
+
+	// A LinuxGSM server, which has already been added through a symlink
+	server := filepath.Join(root, "serverfiles")
+	writeFakeInstallation(t, server, "FactoryServer.sh", platforms[3])
+
+	addedPath := server
+	if runtime.GOOS != "windows" {
+		addedPath = filepath.Join(root, "linked-serverfiles")
+		testza.AssertNoError(t, os.Symlink(server, addedPath))
+	}
+
+	_, err = ctx.Installations.AddInstallation(context.Background(), ctx, addedPath, ctx.Profiles.SelectedProfile)
+	testza.AssertNoError(t, err)
+
+	// A directory with a version file, but without the executable of the game
+	incomplete := filepath.Join(root, "incomplete")
+	testza.AssertNoError(t, os.MkdirAll(filepath.Join(incomplete, filepath.Dir(platforms[0].VersionPath)), 0o755))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(incomplete, platforms[0].VersionPath), []byte(`{"Changelist": 365306}`), 0o644))
+
+	discovered := ctx.Installations.discover(context.Background(), ctx, discoverySources{
+		steam:   []string{steam, filepath.Join(root, "missing")},
+		epic:    []string{epic},
+		servers: []string{server, filepath.Join(root, "Epic", "Other"), incomplete},
+	})
+
+	testza.AssertLen(t, discovered, 4)
+
+	byPath := make(map[string]*DiscoveredInstallation)
+	for _, install := range discovered {
+		byPath[install.Path] = install
+	}
+
+	game := byPath[filepath.Join(steam, "steamapps", "common", "Satisfactory")]
+	testza.AssertNotNil(t, game)
+	testza.AssertEqual(t, "steam", game.Source)
+	testza.AssertEqual(t, "Windows", game.Platform)
+
+	dedicated := byPath[filepath.Join(library, "steamapps", "common", "SatisfactoryDedicatedServer")]
+	testza.AssertNotNil(t, dedicated)
+	testza.AssertEqual(t, "LinuxServer", dedicated.Platform)
+	testza.AssertFalse(t, dedicated.Added)
+
+	testza.AssertNotNil(t, byPath[filepath.Join(root, "Epic", "Satisfactory")])
+	testza.AssertEqual(t, "epic", byPath[filepath.Join(root, "Epic", "Satisfactory")].Source)
+
+	testza.AssertNotNil(t, byPath[server])
+	testza.AssertTrue(t, byPath[server].Added)
+
+	err = ctx.Wipe(context.Background())
+	testza.AssertNoError(t, err)
+}
+
+func TestReadVDF(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "libraryfolders.vdf")
+
+	// The format used before 2021, with escaped Windows paths
+	testza.AssertNoError(t, os.WriteFile(file, []byte(`"LibraryFolders"
+{
+	"TimeNextStatsReport"		"1600000000"
+	"1"		"D:\\SteamLibrary"
+}`), 0o644))
+
+	values, err := readVDF(file)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `D:\SteamLibrary`, vdfSection(values, "libraryfolders")["1"])
+
+	testza.AssertNoError(t, os.WriteFile(file, []byte(`"LibraryFolders" { "1" "D:`), 0o644))
+	_, err = readVDF(file)
+	testza.AssertNotNil(t, err)
+
+	testza.AssertNoError(t, os.WriteFile(file, []byte(`"LibraryFolders" { "1" "D:" `), 0o644))
+	_, err = readVDF(file)
+	testza.AssertNotNil(t, err)
+}
@@ -0,0 +1,257 @@
+package disk
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk/disktest"
+)
+
+func init() {
+	cfg.SetDefaults()
+}
+
+func TestLocalDisk(t *testing.T) {
+	root := t.TempDir()
+
+	d, err := FromPath(root)
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, root)
+}
+
+func TestFTPDisk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	address := disktest.StartFTPServer(t, t.TempDir(), nil)
+
+	d, err := FromPath("ftp://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, "/")
+}
+
+func TestSFTPDisk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	root := t.TempDir()
+	address := disktest.StartSFTPServer(t, "user", "pass", false)
+
+	d, err := FromPath("sftp://user:pass@" + address + root)
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, root)
+}
+
+func TestWebDAVDisk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	address := startWebDAVServer(t, "user", "pass", false)
+
+	d, err := FromPath("webdav://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, "/")
+}
+
+func TestMemoryDisk(t *testing.T) {
+	d, err := FromPath("memory://" + t.Name() + "/")
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, "/")
+
+	// Paths with the same name share their files
+	other, err := FromPath("memory://" + t.Name() + "/other")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, d, other)
+
+	// Removed disks are dropped with their files
+	RemoveMemory(t.Name())
+
+	removed, err := FromPath("memory://" + t.Name() + "/")
+	testza.AssertNoError(t, err)
+	testza.AssertNotEqual(t, d, removed)
+
+	exists, err := removed.Exists(context.Background(), "/stream.bin")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+}
+
+// testDiskConformance checks the behavior every disk implementation must share.
+// root must be an existing, empty directory of the disk.
+func testDiskConformance(t *testing.T, d Disk, root string) {
+	t.Helper()
+
+	ctx := context.Background()
+	join := func(elem ...string) string {
+		if root == "/" {
+			return path.Join(append([]string{root}, elem...)...)
+		}
+		return filepath.Join(append([]string{root}, elem...)...)
+	}
+
+	t.Run("WriteRead", func(t *testing.T) {
+		p := join("write.txt")
+
+		exists, err := d.Exists(ctx, p)
+		testza.AssertNoError(t, err)
+		testza.AssertFalse(t, exists)
+
+		testza.AssertNoError(t, d.Write(ctx, p, []byte("hello")))
+
+		exists, err = d.Exists(ctx, p)
+		testza.AssertNoError(t, err)
+		testza.AssertTrue(t, exists)
+
+		data, err := d.Read(ctx, p)
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, []byte("hello"), data)
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		p := join("stream.bin")
+		content := make([]byte, 256*1024)
+		_, _ = rand.Read(content)
+
+		w, err := d.Open(ctx, p, os.O_CREATE|os.O_RDWR)
+		testza.AssertNoError(t, err)
+		_, err = w.Write(content)
+		testza.AssertNoError(t, err)
+		testza.AssertNoError(t, w.Close())
+
+		// Some backends finish the upload in the background
+		testza.AssertNoError(t, waitFor(func() bool {
+			info, err := d.Stat(ctx, p)
+			return err == nil && info.Size == int64(len(content))
+		}))
+
+		r, err := d.OpenReader(ctx, p)
+		testza.AssertNoError(t, err)
+		read, err := io.ReadAll(r)
+		testza.AssertNoError(t, err)
+		testza.AssertNoError(t, r.Close())
+		testza.AssertEqual(t, content, read)
+
+		_, err = d.OpenReader(ctx, join("missing.bin"))
+		testza.AssertNotNil(t, err)
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		p := join("stat.txt")
+		testza.AssertNoError(t, d.Write(ctx, p, []byte("12345")))
+
+		info, err := d.Stat(ctx, p)
+		testza.AssertNoError(t, err)
+		testza.AssertFalse(t, info.IsDir)
+		testza.AssertEqual(t, int64(5), info.Size)
+		testza.AssertFalse(t, info.ModTime.IsZero())
+		// Listings can be precise to the minute only
+		testza.AssertTrue(t, time.Since(info.ModTime) < 24*time.Hour)
+
+		testza.AssertNoError(t, d.MkDir(ctx, join("stat-dir")))
+		info, err = d.Stat(ctx, join("stat-dir"))
+		testza.AssertNoError(t, err)
+		testza.AssertTrue(t, info.IsDir)
+
+		_, err = d.Stat(ctx, join("missing.txt"))
+		testza.AssertTrue(t, errors.Is(err, os.ErrNotExist), err)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		from := join("rename-from.txt")
+		to := join("rename-to.txt")
+
+		testza.AssertNoError(t, d.Write(ctx, from, []byte("first")))
+		testza.AssertNoError(t, d.Rename(ctx, from, to))
+
+		exists, err := d.Exists(ctx, from)
+		testza.AssertNoError(t, err)
+		testza.AssertFalse(t, exists)
+
+		data, err := d.Read(ctx, to)
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, []byte("first"), data)
+
+		// The destination is replaced
+		testza.AssertNoError(t, d.Write(ctx, from, []byte("second")))
+		testza.AssertNoError(t, d.Rename(ctx, from, to))
+
+		data, err = d.Read(ctx, to)
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, []byte("second"), data)
+	})
+
+	t.Run("Chmod", func(t *testing.T) {
+		p := join("chmod.sh")
+		testza.AssertNoError(t, d.Write(ctx, p, []byte("#!/bin/sh")))
+
+		err := d.Chmod(ctx, p, 0o755)
+		if errors.Is(err, errors.ErrUnsupported) {
+			return
+		}
+		testza.AssertNoError(t, err)
+	})
+
+	t.Run("Directories", func(t *testing.T) {
+		dir := join("dir", "nested")
+		testza.AssertNoError(t, d.MkDir(ctx, dir))
+		testza.AssertNoError(t, d.Write(ctx, join("dir", "nested", "a.txt"), []byte("a")))
+		testza.AssertNoError(t, d.Write(ctx, join("dir", "b.txt"), []byte("b")))
+
+		entries, err := d.ReadDir(ctx, join("dir"))
+		testza.AssertNoError(t, err)
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+			testza.AssertEqual(t, entry.Name() == "nested", entry.IsDir())
+		}
+		slices.Sort(names)
+		testza.AssertEqual(t, []string{"b.txt", "nested"}, names)
+
+		testza.AssertNoError(t, d.Remove(ctx, join("dir")))
+
+		exists, err := d.Exists(ctx, join("dir"))
+		testza.AssertNoError(t, err)
+		testza.AssertFalse(t, exists)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := d.Exists(cancelled, join("write.txt"))
+		testza.AssertTrue(t, errors.Is(err, context.Canceled), err)
+	})
+}
+
+func waitFor(condition func() bool) error {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("condition not met")
+}
@@ -0,0 +1,188 @@
+// Package disktest provides FTP and SFTP servers for tests of the remote disks
+// and of the installations using them.
+package disktest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	osexec "os/exec"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/pkg/sftp"
+	"goftp.io/server/v2"
+	"goftp.io/server/v2/driver/file"
+	"golang.org/x/crypto/ssh"
+)
+
+// StartFTPServer serves the root directory over FTP for user "user" with password "pass" until the test ends,
+// and returns the address of the server.
+// The options are modified by configure before the server is started.
+func StartFTPServer(t *testing.T, root string, configure func(options *server.Options)) string {
+	t.Helper()
+
+	// TLS is only set up when the server creates its own listener, so reserve a port for it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testza.AssertNoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	testza.AssertNoError(t, listener.Close())
+
+	driver, err := file.NewDriver(root)
+	testza.AssertNoError(t, err)
+
+	options := &server.Options{
+		Driver: driver,
+		Auth: &server.SimpleAuth{
+			Name:     "user",
+			Password: "pass",
+		},
+		Hostname: "127.0.0.1",
+		Port:     port,
+		Perm:     server.NewSimplePerm("root", "root"),
+		Logger:   &server.DiscardLogger{},
+	}
+	if configure != nil {
+		configure(options)
+	}
+
+	s, err := server.NewServer(options)
+	testza.AssertNoError(t, err)
+
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = s.Shutdown()
+	})
+
+	address := net.JoinHostPort("127.0.0.1", fmt.Sprint(port))
+	testza.AssertNoError(t, waitFor(func() bool {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}))
+
+	return address
+}
+
+// StartSFTPServer serves the local filesystem over SFTP until the test ends,
+// and returns the address of the server.
+// If exec is enabled, commands are run with the local shell.
+func StartSFTPServer(t *testing.T, user string, password string, exec bool) string {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	testza.AssertNoError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	testza.AssertNoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials for %s", conn.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testza.AssertNoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config, exec)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig, exec bool) {
+	defer conn.Close()
+
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for request := range channelRequests {
+				switch {
+				case request.Type == "subsystem" && string(request.Payload[4:]) == "sftp":
+					_ = request.Reply(true, nil)
+					go func() {
+						defer channel.Close()
+
+						s, err := sftp.NewServer(channel)
+						if err != nil {
+							return
+						}
+						_ = s.Serve()
+					}()
+				case request.Type == "exec" && exec:
+					_ = request.Reply(true, nil)
+					go runCommand(channel, string(request.Payload[4:]))
+				default:
+					_ = request.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+func runCommand(channel ssh.Channel, command string) {
+	defer channel.Close()
+
+	cmd := osexec.Command("sh", "-c", command)
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+
+	status := uint32(0)
+	if err := cmd.Run(); err != nil {
+		status = 1
+		var exitErr *osexec.ExitError
+		if errors.As(err, &exitErr) {
+			status = uint32(exitErr.ExitCode())
+		}
+	}
+
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
+func waitFor(condition func() bool) error {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("condition not met")
+}
@@ -0,0 +1,631 @@
+package disk
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+var _ Disk = (*dockerDisk)(nil)
+
+// dockerDisk accesses the filesystem of a container through the Docker Engine API.
+// Files are transferred with the archive endpoints, while removing, renaming and
+// changing permissions runs commands in the container.
+type dockerDisk struct {
+	client    *http.Client
+	container string
+	path      string
+}
+
+type dockerEntry struct {
+	name  string
+	isDir bool
+}
+
+func (f dockerEntry) IsDir() bool {
+	return f.isDir
+}
+
+func (f dockerEntry) Name() string {
+	return f.name
+}
+
+// dockerPathStat is the stat of a path returned by the archive endpoints
+type dockerPathStat struct {
+	Name  string      `json:"name"`
+	Size  int64       `json:"size"`
+	Mode  os.FileMode `json:"mode"`
+	Mtime time.Time   `json:"mtime"`
+}
+
+func newDocker(path string) (Disk, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse docker url: %w", err)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing container in docker url: %s", path)
+	}
+
+	// Same as the docker cli
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse docker host: %w", err)
+	}
+
+	var network, address string
+	switch hostURL.Scheme {
+	case "unix":
+		network, address = "unix", hostURL.Path
+	case "tcp":
+		network, address = "tcp", hostURL.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker host: %s", host)
+	}
+
+	dialer := &net.Dialer{Timeout: viper.GetDuration("connect-timeout")}
+
+	d := &dockerDisk{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, address)
+				},
+				ResponseHeaderTimeout: viper.GetDuration("read-timeout"),
+			},
+		},
+		container: u.Host,
+		path:      u.Path,
+	}
+
+	res, err := d.request(context.Background(), http.MethodGet, "/containers/"+url.PathEscape(d.container)+"/json", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to docker: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := dockerStatus(res, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", d.container, err)
+	}
+
+	slog.Info("connected to docker", slog.String("container", d.container))
+
+	return d, nil
+}
+
+func (l *dockerDisk) Exists(ctx context.Context, path string) (bool, error) {
+	slog.Debug("checking if file exists", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	_, err := l.Stat(ctx, path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *dockerDisk) Read(ctx context.Context, path string) ([]byte, error) {
+	slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	reader, err := l.OpenReader(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (l *dockerDisk) Write(ctx context.Context, path string, data []byte) error {
+	slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	if err := l.upload(ctx, path, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *dockerDisk) Remove(ctx context.Context, path string) error {
+	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	if err := l.exec(ctx, "rm", "-rf", clean(path)); err != nil {
+		return fmt.Errorf("failed to delete path: %w", err)
+	}
+
+	return nil
+}
+
+func (l *dockerDisk) MkDir(ctx context.Context, p string) error {
+	// Directories can only be extracted into an existing directory
+	existing := clean(p)
+	var missing []string
+	for existing != "/" && existing != "." {
+		info, err := l.Stat(ctx, existing)
+		if err == nil {
+			if !info.IsDir {
+				return fmt.Errorf("failed to make directory: %s is not a directory", existing)
+			}
+			break
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to make directory: %w", err)
+		}
+
+		missing = append([]string{path.Base(existing)}, missing...)
+		existing = path.Dir(existing)
+	}
+
+	if len(missing) == 0 {
+		// Already exists
+		return nil
+	}
+
+	slog.Debug("making directory", slog.String("dir", clean(p)), slog.String("cwd", existing), slog.String("schema", "docker"))
+
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	for i := range missing {
+		if err := writer.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     strings.Join(missing[:i+1], "/") + "/",
+			Mode:     0o755,
+			ModTime:  time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to make directory: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+
+	if err := l.putArchive(ctx, existing, buf); err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+
+	return nil
+}
+
+// ReadDir lists the directory from its archive.
+// The archive contains the whole tree, so listing large directories is slow.
+func (l *dockerDisk) ReadDir(ctx context.Context, p string) ([]Entry, error) {
+	slog.Debug("reading directory", slog.String("path", clean(p)), slog.String("schema", "docker"))
+
+	res, err := l.getArchive(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	reader := tar.NewReader(res.Body)
+
+	// The first entry is the directory itself
+	dir, err := reader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", err)
+	}
+
+	if dir.Typeflag != tar.TypeDir {
+		return nil, fmt.Errorf("failed to list files in directory: %s is not a directory", clean(p))
+	}
+
+	prefix := strings.TrimSuffix(dir.Name, "/") + "/"
+
+	var entries []Entry
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in directory: %w", err)
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(header.Name, prefix), "/")
+		if name == "" || strings.Contains(name, "/") {
+			continue
+		}
+
+		entries = append(entries, dockerEntry{
+			name:  name,
+			isDir: header.Typeflag == tar.TypeDir,
+		})
+	}
+
+	return entries, nil
+}
+
+// dockerWriter buffers the written file in a temporary file,
+// as archives need to know the size of the file before its content
+type dockerWriter struct {
+	*os.File
+	ctx  context.Context
+	disk *dockerDisk
+	path string
+}
+
+func (w *dockerWriter) Close() error {
+	defer os.Remove(w.File.Name())
+	defer w.File.Close()
+
+	size, err := w.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to get file size: %w", err)
+	}
+
+	if _, err := w.File.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind file: %w", err)
+	}
+
+	if err := w.disk.upload(w.ctx, w.path, size, w.File); err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+
+	slog.Debug("write success", slog.String("path", clean(w.path)), slog.String("schema", "docker"))
+
+	return nil
+}
+
+func (l *dockerDisk) Open(ctx context.Context, path string, _ int) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	f, err := os.CreateTemp("", "ficsit-docker-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	return contextWriter{ctx: ctx, WriteCloser: &dockerWriter{
+		File: f,
+		ctx:  ctx,
+		disk: l,
+		path: path,
+	}}, nil
+}
+
+// dockerReader reads a single file from an archive
+type dockerReader struct {
+	*tar.Reader
+	body io.Closer
+}
+
+func (r dockerReader) Close() error {
+	return r.body.Close() //nolint:wrapcheck
+}
+
+func (l *dockerDisk) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	slog.Debug("opening for reading", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	res, err := l.getArchive(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve path: %w", err)
+	}
+
+	reader := tar.NewReader(res.Body)
+
+	header, err := reader.Next()
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if header.Typeflag != tar.TypeReg {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("failed to retrieve path: %s is not a file", clean(path))
+	}
+
+	return dockerReader{
+		Reader: reader,
+		body:   res.Body,
+	}, nil
+}
+
+func (l *dockerDisk) Stat(ctx context.Context, path string) (FileInfo, error) {
+	slog.Debug("getting file info", slog.String("path", clean(path)), slog.String("schema", "docker"))
+
+	res, err := l.archiveRequest(ctx, http.MethodHead, path, nil)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := dockerStatus(res, http.StatusOK); err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get path info: %s: %w", clean(path), err)
+	}
+
+	stat, err := parseDockerPathStat(res.Header.Get("X-Docker-Container-Path-Stat"))
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	return FileInfo{
+		ModTime: stat.Mtime,
+		Size:    stat.Size,
+		IsDir:   stat.Mode.IsDir(),
+	}, nil
+}
+
+func (l *dockerDisk) Rename(ctx context.Context, from string, to string) error {
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "docker"))
+
+	if err := l.exec(ctx, "mv", "-f", clean(from), clean(to)); err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+
+	return nil
+}
+
+func (l *dockerDisk) Chmod(ctx context.Context, path string, mode os.FileMode) error {
+	slog.Debug("changing permissions", slog.String("path", clean(path)), slog.String("mode", mode.String()), slog.String("schema", "docker"))
+
+	if err := l.exec(ctx, "chmod", fmt.Sprintf("%o", mode.Perm()), clean(path)); err != nil {
+		return fmt.Errorf("failed to change permissions: %w", err)
+	}
+
+	return nil
+}
+
+// upload stores a single file by extracting an archive of it into its parent directory
+func (l *dockerDisk) upload(ctx context.Context, p string, size int64, content io.Reader) error {
+	reader, writer := io.Pipe()
+
+	go func() {
+		tarWriter := tar.NewWriter(writer)
+		err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Base(clean(p)),
+			Size:     size,
+			Mode:     0o644,
+			ModTime:  time.Now(),
+		})
+		if err == nil {
+			_, err = io.Copy(tarWriter, content)
+		}
+		if err == nil {
+			err = tarWriter.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+
+	err := l.putArchive(ctx, path.Dir(clean(p)), reader)
+
+	// Unblock the archive writer if the request did not read everything
+	_ = reader.Close()
+
+	return err
+}
+
+func (l *dockerDisk) putArchive(ctx context.Context, dir string, archive io.Reader) error {
+	res, err := l.archiveRequest(ctx, http.MethodPut, dir, archive)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	return dockerStatus(res, http.StatusOK)
+}
+
+func (l *dockerDisk) getArchive(ctx context.Context, path string) (*http.Response, error) {
+	res, err := l.archiveRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dockerStatus(res, http.StatusOK); err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (l *dockerDisk) archiveRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	endpoint := "/containers/" + url.PathEscape(l.container) + "/archive?" + url.Values{"path": {clean(path)}}.Encode()
+
+	var header http.Header
+	if body != nil {
+		header = http.Header{"Content-Type": {"application/x-tar"}}
+	}
+
+	return l.request(ctx, method, endpoint, body, header)
+}
+
+// exec runs a command in the container and waits for it to finish
+func (l *dockerDisk) exec(ctx context.Context, command ...string) error {
+	create, err := json.Marshal(map[string]any{
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"Cmd":          command,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode exec: %w", err)
+	}
+
+	res, err := l.request(ctx, http.MethodPost, "/containers/"+url.PathEscape(l.container)+"/exec", bytes.NewReader(create), http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if err := dockerStatus(res, http.StatusCreated); err != nil {
+		return fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return fmt.Errorf("failed to decode exec: %w", err)
+	}
+
+	start, err := l.request(ctx, http.MethodPost, "/exec/"+url.PathEscape(created.ID)+"/start", strings.NewReader(`{"Detach":false,"Tty":false}`), http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return err
+	}
+
+	defer start.Body.Close()
+
+	if err := dockerStatus(start, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to start exec: %w", err)
+	}
+
+	// The output is streamed until the command exits
+	output, err := readDockerStream(start.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read exec output: %w", err)
+	}
+
+	for {
+		inspect, err := l.request(ctx, http.MethodGet, "/exec/"+url.PathEscape(created.ID)+"/json", nil, nil)
+		if err != nil {
+			return err
+		}
+
+		var state struct {
+			Running  bool `json:"Running"`
+			ExitCode int  `json:"ExitCode"`
+		}
+
+		err = dockerStatus(inspect, http.StatusOK)
+		if err == nil {
+			err = json.NewDecoder(inspect.Body).Decode(&state)
+		}
+		_ = inspect.Body.Close()
+
+		if err != nil {
+			return fmt.Errorf("failed to inspect exec: %w", err)
+		}
+
+		if !state.Running {
+			if state.ExitCode != 0 {
+				return fmt.Errorf("%s exited with code %d: %s", strings.Join(command, " "), state.ExitCode, strings.TrimSpace(output))
+			}
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func (l *dockerDisk) request(ctx context.Context, method string, endpoint string, body io.Reader, header http.Header) (*http.Response, error) {
+	// The host is ignored, as every request is sent to the docker socket
+	req, err := http.NewRequestWithContext(ctx, method, "http://docker"+endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return res, nil
+}
+
+// readDockerStream reads the multiplexed stdout and stderr of a command
+func readDockerStream(r io.Reader) (string, error) {
+	output := &strings.Builder{}
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return output.String(), nil
+			}
+			return output.String(), err //nolint:wrapcheck
+		}
+
+		if _, err := io.CopyN(output, r, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return output.String(), err //nolint:wrapcheck
+		}
+	}
+}
+
+func parseDockerPathStat(header string) (dockerPathStat, error) {
+	var stat dockerPathStat
+
+	data, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return stat, fmt.Errorf("failed to decode path stat: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &stat); err != nil {
+		return stat, fmt.Errorf("failed to parse path stat: %w", err)
+	}
+
+	return stat, nil
+}
+
+// dockerStatus returns the error message of the response if its status is not the expected one
+func dockerStatus(res *http.Response, expected int) error {
+	if res.StatusCode == expected {
+		return nil
+	}
+
+	var message struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&message)
+	if message.Message == "" {
+		message.Message = res.Status
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", message.Message, os.ErrNotExist)
+	}
+
+	return errors.New(message.Message)
+}
@@ -0,0 +1,286 @@
+package disk
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+// fakeDocker implements the endpoints of the Docker Engine API used by the docker disk,
+// with the filesystem of the container stored in root
+type fakeDocker struct {
+	root      string
+	container string
+
+	mu    sync.Mutex
+	execs []fakeExec
+}
+
+type fakeExec struct {
+	cmd      []string
+	exitCode int
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeError := func(status int, err error) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 3 && parts[0] == "containers" && parts[1] != f.container:
+		writeError(http.StatusNotFound, errors.New("No such container: "+parts[1]))
+	case len(parts) == 3 && parts[0] == "containers" && parts[2] == "json":
+		_ = json.NewEncoder(w).Encode(map[string]string{"Id": f.container})
+	case len(parts) == 3 && parts[0] == "containers" && parts[2] == "archive":
+		f.archive(w, r, writeError)
+	case len(parts) == 3 && parts[0] == "containers" && parts[2] == "exec":
+		var body struct {
+			Cmd []string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+
+		f.mu.Lock()
+		f.execs = append(f.execs, fakeExec{cmd: body.Cmd})
+		id := strconv.Itoa(len(f.execs) - 1)
+		f.mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"Id": id})
+	case len(parts) == 3 && parts[0] == "exec" && parts[2] == "start":
+		id, _ := strconv.Atoi(parts[1])
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if err := f.run(f.execs[id].cmd); err != nil {
+			f.execs[id].exitCode = 1
+
+			// Written to stderr
+			header := make([]byte, 8)
+			header[0] = 2
+			binary.BigEndian.PutUint32(header[4:], uint32(len(err.Error())))
+			_, _ = w.Write(append(header, err.Error()...))
+		}
+	case len(parts) == 3 && parts[0] == "exec" && parts[2] == "json":
+		id, _ := strconv.Atoi(parts[1])
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"Running": false, "ExitCode": f.execs[id].exitCode})
+	default:
+		writeError(http.StatusNotFound, errors.New("page not found"))
+	}
+}
+
+func (f *fakeDocker) local(path string) string {
+	return filepath.Join(f.root, filepath.FromSlash(path))
+}
+
+func (f *fakeDocker) archive(w http.ResponseWriter, r *http.Request, writeError func(int, error)) {
+	target := f.local(r.URL.Query().Get("path"))
+
+	info, err := os.Stat(target)
+	if err != nil {
+		writeError(http.StatusNotFound, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		stat, _ := json.Marshal(dockerPathStat{
+			Name:  info.Name(),
+			Size:  info.Size(),
+			Mode:  info.Mode(),
+			Mtime: info.ModTime(),
+		})
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+	case http.MethodGet:
+		writer := tar.NewWriter(w)
+		defer writer.Close()
+
+		_ = filepath.WalkDir(target, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			rel, _ := filepath.Rel(filepath.Dir(target), p)
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+			header.Name = filepath.ToSlash(rel)
+			if entry.IsDir() {
+				header.Name += "/"
+			}
+
+			if err := writer.WriteHeader(header); err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			if entry.Type().IsRegular() {
+				file, err := os.Open(p)
+				if err != nil {
+					return err //nolint:wrapcheck
+				}
+				defer file.Close()
+
+				_, err = io.Copy(writer, file)
+				return err //nolint:wrapcheck
+			}
+
+			return nil
+		})
+	case http.MethodPut:
+		if !info.IsDir() {
+			writeError(http.StatusBadRequest, errors.New("not a directory"))
+			return
+		}
+
+		reader := tar.NewReader(r.Body)
+		for {
+			header, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				writeError(http.StatusBadRequest, err)
+				return
+			}
+
+			p := filepath.Join(target, filepath.FromSlash(header.Name))
+			if header.Typeflag == tar.TypeDir {
+				err = os.MkdirAll(p, 0o755)
+			} else {
+				err = func() error {
+					file, err := os.Create(p)
+					if err != nil {
+						return err //nolint:wrapcheck
+					}
+					defer file.Close()
+
+					_, err = io.Copy(file, reader)
+					return err //nolint:wrapcheck
+				}()
+			}
+
+			if err != nil {
+				writeError(http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeDocker) run(cmd []string) error {
+	switch {
+	case len(cmd) == 3 && cmd[0] == "rm" && cmd[1] == "-rf":
+		return os.RemoveAll(f.local(cmd[2])) //nolint:wrapcheck
+	case len(cmd) == 4 && cmd[0] == "mv" && cmd[1] == "-f":
+		return os.Rename(f.local(cmd[2]), f.local(cmd[3])) //nolint:wrapcheck
+	case len(cmd) == 3 && cmd[0] == "chmod":
+		mode, err := strconv.ParseUint(cmd[1], 8, 32)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		return os.Chmod(f.local(cmd[2]), os.FileMode(mode)) //nolint:wrapcheck
+	}
+
+	return errors.New("command not found: " + strings.Join(cmd, " "))
+}
+
+// startFakeDocker serves the fake Docker Engine API on a unix socket,
+// which is used as DOCKER_HOST until the test ends
+func startFakeDocker(t *testing.T, container string) *fakeDocker {
+	t.Helper()
+
+	fake := &fakeDocker{
+		root:      t.TempDir(),
+		container: container,
+	}
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	testza.AssertNoError(t, err)
+
+	s := httptest.NewUnstartedServer(fake)
+	s.Listener = listener
+	s.Start()
+	t.Cleanup(s.Close)
+
+	t.Setenv("DOCKER_HOST", "unix://"+socket)
+
+	return fake
+}
+
+func TestDockerDisk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	startFakeDocker(t, "server")
+
+	d, err := FromPath("docker://server/")
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, "/")
+}
+
+func TestDockerDiskErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	ctx := context.Background()
+	fake := startFakeDocker(t, "server")
+
+	_, err := FromPath("docker://missing/")
+	testza.AssertTrue(t, errors.Is(err, os.ErrNotExist), err)
+
+	d, err := FromPath("docker://server/")
+	testza.AssertNoError(t, err)
+
+	// Writing into a missing directory fails, the directory has to be made first
+	testza.AssertNotNil(t, d.Write(ctx, "/missing/file.txt", []byte("a")))
+	testza.AssertNoError(t, d.MkDir(ctx, "/missing"))
+	testza.AssertNoError(t, d.Write(ctx, "/missing/file.txt", []byte("a")))
+
+	_, err = d.OpenReader(ctx, "/missing")
+	testza.AssertNotNil(t, err)
+
+	// Failing commands report their output
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(fake.root, "file.txt"), []byte("a"), 0o644))
+	err = d.Rename(ctx, "/missing-source", "/target")
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, err.Error(), "exited with code 1")
+}
@@ -3,24 +3,30 @@ package disk
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
+	"net"
 	"net/textproto"
 	"net/url"
+	"os"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
-	"time"
+	"syscall"
 
 	"github.com/jackc/puddle/v2"
 	"github.com/jlaffaye/ftp"
+	"github.com/spf13/viper"
 )
 
-// TODO Make configurable
-const connectionCount = 5
+// ftpRetries is how many times an operation is retried after losing its connection
+const ftpRetries = 2
 
 var _ Disk = (*ftpDisk)(nil)
 
@@ -47,11 +53,22 @@ func newFTP(path string) (Disk, error) {
 		return nil, fmt.Errorf("failed to parse ftp url: %w", err)
 	}
 
+	options, err := ftpDialOptions(u)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := viper.GetInt("ftp-connections")
+	if connections < 1 {
+		return nil, fmt.Errorf("invalid ftp connection count: %d", connections)
+	}
+
 	pool, err := puddle.NewPool(&puddle.Config[*ftp.ServerConn]{
 		Constructor: func(ctx context.Context) (*ftp.ServerConn, error) {
-			c, failedHidden, err := testFTP(u, ftp.DialWithTimeout(time.Second*5), ftp.DialWithForceListHidden(true))
+			options := append([]ftp.DialOption{ftp.DialWithContext(ctx)}, options...)
+			c, failedHidden, err := testFTP(u, append(options, ftp.DialWithForceListHidden(true))...)
 			if failedHidden {
-				c, _, err = testFTP(u, ftp.DialWithTimeout(time.Second*5))
+				c, _, err = testFTP(u, options...)
 				if err != nil {
 					return nil, err
 				}
@@ -59,14 +76,17 @@ func newFTP(path string) (Disk, error) {
 				return nil, err
 			}
 
-			slog.Info("logged into ftp", slog.Bool("hidden-files", !failedHidden))
+			slog.Info("logged into ftp", slog.Bool("hidden-files", !failedHidden), slog.Bool("tls", u.Scheme == "ftps"))
 
 			return c, nil
 		},
-		MaxSize: connectionCount,
+		Destructor: func(c *ftp.ServerConn) {
+			_ = c.Quit()
+		},
+		MaxSize: int32(connections),
 	})
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to create ftp connection pool: %w", err)
 	}
 
 	return &ftpDisk{
@@ -75,6 +95,70 @@ func newFTP(path string) (Disk, error) {
 	}, nil
 }
 
+// ftpDialOptions returns the options for connecting to the server of the url.
+//
+// ftps urls use explicit TLS by default, and accept the following query parameters:
+//   - tls=implicit to connect with implicit TLS instead
+//   - skip-verify=true to accept any certificate
+//   - fingerprint=<sha256> to only accept the certificate with the provided SHA-256 fingerprint
+func ftpDialOptions(u *url.URL) ([]ftp.DialOption, error) {
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(viper.GetDuration("connect-timeout")),
+		ftp.DialWithShutTimeout(viper.GetDuration("read-timeout")),
+	}
+
+	if u.Scheme != "ftps" {
+		return options, nil
+	}
+
+	query := u.Query()
+
+	tlsConfig := &tls.Config{
+		ServerName: u.Hostname(),
+		// Servers commonly require data connections to resume the session of the control connection
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if skipVerify := query.Get("skip-verify"); skipVerify != "" {
+		skip, err := strconv.ParseBool(skipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip-verify value: %s: %w", skipVerify, err)
+		}
+		tlsConfig.InsecureSkipVerify = skip
+	}
+
+	if fingerprint := query.Get("fingerprint"); fingerprint != "" {
+		expected, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil || len(expected) != sha256.Size {
+			return nil, fmt.Errorf("invalid certificate fingerprint, expected a SHA-256 hash: %s", fingerprint)
+		}
+
+		// The pinned certificate replaces the verification of the certificate chain
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server did not send a certificate")
+			}
+
+			actual := sha256.Sum256(state.PeerCertificates[0].Raw)
+			if !bytes.Equal(actual[:], expected) {
+				return fmt.Errorf("server certificate fingerprint %x does not match %x", actual, expected)
+			}
+
+			return nil
+		}
+	}
+
+	switch mode := query.Get("tls"); mode {
+	case "", "explicit":
+		return append(options, ftp.DialWithExplicitTLS(tlsConfig)), nil
+	case "implicit":
+		return append(options, ftp.DialWithTLS(tlsConfig)), nil
+	default:
+		return nil, fmt.Errorf("invalid tls mode, expected explicit or implicit: %s", mode)
+	}
+}
+
 func testFTP(u *url.URL, options ...ftp.DialOption) (*ftp.ServerConn, bool, error) {
 	c, err := ftp.Dial(u.Host, options...)
 	if err != nil {
@@ -83,23 +167,25 @@ func testFTP(u *url.URL, options ...ftp.DialOption) (*ftp.ServerConn, bool, erro
 
 	password, _ := u.User.Password()
 	if err := c.Login(u.User.Username(), password); err != nil {
+		_ = c.Quit()
 		return nil, false, fmt.Errorf("failed to login: %w", err)
 	}
 
 	_, err = c.List("/")
 	if err != nil {
+		_ = c.Quit()
 		return nil, true, fmt.Errorf("failed listing dir: %w", err)
 	}
 
 	return c, false, nil
 }
 
-func (l *ftpDisk) existsWithLock(res *puddle.Resource[*ftp.ServerConn], p string) (bool, error) {
+func (l *ftpDisk) existsWithLock(conn *ftp.ServerConn, p string) (bool, error) {
 	slog.Debug("checking if file exists", slog.String("path", clean(p)), slog.String("schema", "ftp"))
 
 	var protocolError *textproto.Error
 
-	_, err := res.Value().GetEntry(clean(p))
+	_, err := conn.GetEntry(clean(p))
 	if err == nil {
 		return true, nil
 	}
@@ -127,7 +213,7 @@ func (l *ftpDisk) existsWithLock(res *puddle.Resource[*ftp.ServerConn], p string
 	// In case MLST is not supported, we can try to LIST the target path.
 	// We can be sure that List() will actually execute LIST and not MLSD,
 	// since MLST was not supported in the previous step.
-	entries, err := res.Value().List(clean(p))
+	entries, err := conn.List(clean(p))
 	if err == nil {
 		if len(entries) > 0 {
 			// Some server implementations return an empty list for a nonexistent path,
@@ -150,7 +236,7 @@ func (l *ftpDisk) existsWithLock(res *puddle.Resource[*ftp.ServerConn], p string
 	// or it does not exist and the server is a weird implementation.
 
 	// List the parent directory to determine if the path exists
-	dir, err := l.readDirLock(res, path.Dir(clean(p)))
+	dir, err := l.readDirLock(conn, path.Dir(clean(p)))
 	if err == nil {
 		found := false
 		for _, entry := range dir {
@@ -173,69 +259,64 @@ func (l *ftpDisk) existsWithLock(res *puddle.Resource[*ftp.ServerConn], p string
 	return false, fmt.Errorf("failed to list parent path: %w", err)
 }
 
-func (l *ftpDisk) Exists(p string) (bool, error) {
-	res, err := l.acquire()
-	if err != nil {
-		return false, err
-	}
-
-	defer res.Release()
+func (l *ftpDisk) Exists(ctx context.Context, p string) (bool, error) {
+	var exists bool
+	err := l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		var err error
+		exists, err = l.existsWithLock(conn, p)
+		return err
+	})
 
-	return l.existsWithLock(res, p)
+	return exists, err
 }
 
-func (l *ftpDisk) Read(path string) ([]byte, error) {
-	res, err := l.acquire()
-	if err != nil {
-		return nil, err
-	}
+func (l *ftpDisk) Read(ctx context.Context, path string) ([]byte, error) {
+	var data []byte
+	err := l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 
-	defer res.Release()
+		f, err := conn.Retr(clean(path))
+		if err != nil {
+			return fmt.Errorf("failed to retrieve path: %w", err)
+		}
 
-	slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+		defer f.Close()
 
-	f, err := res.Value().Retr(clean(path))
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve path: %w", err)
-	}
-
-	defer f.Close()
+		data, err = io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
 
-	data, err := io.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
+		return nil
+	})
 
-	return data, nil
+	return data, err
 }
 
-func (l *ftpDisk) Write(path string, data []byte) error {
-	res, err := l.acquire()
-	if err != nil {
-		return err
-	}
-
-	defer res.Release()
-
-	slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
-	if err := res.Value().Stor(clean(path), bytes.NewReader(data)); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
+func (l *ftpDisk) Write(ctx context.Context, path string, data []byte) error {
+	return l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+		if err := conn.Stor(clean(path), bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (l *ftpDisk) Remove(path string) error {
-	res, err := l.acquire()
+func (l *ftpDisk) Remove(ctx context.Context, path string) (err error) {
+	conn, err := l.acquire(ctx)
 	if err != nil {
 		return err
 	}
 
-	defer res.Release()
+	defer func() {
+		conn.done(err)
+	}()
 
 	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "ftp"))
-	if err := res.Value().Delete(clean(path)); err != nil {
-		if err := res.Value().RemoveDirRecur(clean(path)); err != nil {
+	if err := conn.Value().Delete(clean(path)); err != nil {
+		if err := conn.Value().RemoveDirRecur(clean(path)); err != nil {
 			return fmt.Errorf("failed to delete path: %w", err)
 		}
 	}
@@ -243,17 +324,17 @@ func (l *ftpDisk) Remove(path string) error {
 	return nil
 }
 
-func (l *ftpDisk) MkDir(p string) error {
-	res, err := l.acquire()
-	if err != nil {
-		return err
-	}
-
-	defer res.Release()
+func (l *ftpDisk) MkDir(ctx context.Context, p string) error {
+	// Directories created before a connection was lost are found again on retry
+	return l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		return l.mkDirLock(conn, p)
+	})
+}
 
+func (l *ftpDisk) mkDirLock(conn *ftp.ServerConn, p string) error {
 	lastExistingDir := clean(p)
 	for lastExistingDir != "/" && lastExistingDir != "." {
-		foundDir, err := l.existsWithLock(res, lastExistingDir)
+		foundDir, err := l.existsWithLock(conn, lastExistingDir)
 		if err != nil {
 			return err
 		}
@@ -276,19 +357,19 @@ func (l *ftpDisk) MkDir(p string) error {
 		return nil
 	}
 
-	if err := res.Value().ChangeDir(lastExistingDir); err != nil {
+	if err := conn.ChangeDir(lastExistingDir); err != nil {
 		return fmt.Errorf("failed to enter directory: %w", err)
 	}
 
 	split := strings.Split(clean(remainingDirs)[1:], "/")
 	for _, s := range split {
 		slog.Debug("making directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
-		if err := res.Value().MakeDir(s); err != nil {
+		if err := conn.MakeDir(s); err != nil {
 			return fmt.Errorf("failed to make directory: %w", err)
 		}
 
 		slog.Debug("entering directory", slog.String("dir", s), slog.String("cwd", lastExistingDir), slog.String("schema", "ftp"))
-		if err := res.Value().ChangeDir(s); err != nil {
+		if err := conn.ChangeDir(s); err != nil {
 			return fmt.Errorf("failed to enter directory: %w", err)
 		}
 		lastExistingDir = path.Join(lastExistingDir, s)
@@ -297,15 +378,13 @@ func (l *ftpDisk) MkDir(p string) error {
 	return nil
 }
 
-func (l *ftpDisk) ReadDir(path string) ([]Entry, error) {
-	res, err := l.acquire()
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Release()
-
-	entries, err := l.readDirLock(res, path)
+func (l *ftpDisk) ReadDir(ctx context.Context, path string) ([]Entry, error) {
+	var entries []Entry
+	err := l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		var err error
+		entries, err = l.readDirLock(conn, path)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -315,10 +394,10 @@ func (l *ftpDisk) ReadDir(path string) ([]Entry, error) {
 	return entries, nil
 }
 
-func (l *ftpDisk) readDirLock(res *puddle.Resource[*ftp.ServerConn], path string) ([]Entry, error) {
+func (l *ftpDisk) readDirLock(conn *ftp.ServerConn, path string) ([]Entry, error) {
 	slog.Debug("reading directory", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 
-	dir, err := res.Value().List(clean(path))
+	dir, err := conn.List(clean(path))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files in directory: %w", err)
 	}
@@ -333,8 +412,8 @@ func (l *ftpDisk) readDirLock(res *puddle.Resource[*ftp.ServerConn], path string
 	return entries, nil
 }
 
-func (l *ftpDisk) Open(path string, _ int) (io.WriteCloser, error) {
-	res, err := l.acquire()
+func (l *ftpDisk) Open(ctx context.Context, path string, _ int) (io.WriteCloser, error) {
+	conn, err := l.acquire(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -343,23 +422,184 @@ func (l *ftpDisk) Open(path string, _ int) (io.WriteCloser, error) {
 
 	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 
+	stored := &ftpWriter{ctx: ctx, PipeWriter: writer, done: make(chan struct{})}
+
 	go func() {
-		defer res.Release()
+		defer close(stored.done)
 
-		err := res.Value().Stor(clean(path), reader)
+		err := conn.Value().Stor(clean(path), reader)
+		conn.done(err)
 		if err != nil {
-			slog.Error("failed to store file", slog.Any("err", err))
+			stored.err = fmt.Errorf("failed to store file: %w", err)
+			// Unblock the writer, as nothing is reading from the pipe anymore
+			_ = reader.CloseWithError(stored.err)
+			return
 		}
 		slog.Debug("write success", slog.String("path", clean(path)), slog.String("schema", "ftp"))
 	}()
 
-	return writer, nil
+	return contextWriter{ctx: ctx, WriteCloser: stored}, nil
+}
+
+// ftpWriter waits for the transfer to finish once it is closed, and returns its error
+type ftpWriter struct {
+	ctx context.Context
+	*io.PipeWriter
+	done chan struct{}
+	err  error
+}
+
+func (w *ftpWriter) Close() error {
+	// A cancelled write fails the transfer instead of storing a truncated file
+	_ = w.PipeWriter.CloseWithError(w.ctx.Err())
+	<-w.done
+	return w.err
+}
+
+// ftpReader returns the connection of a transfer to the pool once it is closed
+type ftpReader struct {
+	*ftp.Response
+	conn *ftpConnection
+}
+
+func (r ftpReader) Close() error {
+	err := r.Response.Close()
+	r.conn.done(err)
+	return err //nolint:wrapcheck
 }
 
-func (l *ftpDisk) goHome(res *puddle.Resource[*ftp.ServerConn]) error {
+func (l *ftpDisk) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	conn, err := l.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("opening for reading", slog.String("path", clean(path)), slog.String("schema", "ftp"))
+
+	response, err := conn.Value().Retr(clean(path))
+	if err != nil {
+		conn.done(err)
+		return nil, fmt.Errorf("failed to retrieve path: %w", err)
+	}
+
+	return ftpReader{
+		Response: response,
+		conn:     conn,
+	}, nil
+}
+
+func (l *ftpDisk) Stat(ctx context.Context, p string) (FileInfo, error) {
+	var info FileInfo
+	err := l.withRetry(ctx, func(conn *ftp.ServerConn) error {
+		var err error
+		info, err = l.statLock(conn, p)
+		return err
+	})
+
+	return info, err
+}
+
+func (l *ftpDisk) statLock(conn *ftp.ServerConn, p string) (FileInfo, error) {
+	slog.Debug("getting file info", slog.String("path", clean(p)), slog.String("schema", "ftp"))
+
+	entry, err := l.entryWithLock(conn, p)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	info := FileInfo{
+		ModTime: entry.Time,
+		Size:    int64(entry.Size),
+		IsDir:   entry.Type == ftp.EntryTypeFolder,
+	}
+
+	// Listings are not always precise to the second, MDTM is
+	if !info.IsDir && !conn.IsTimePreciseInList() && conn.IsGetTimeSupported() {
+		if modTime, err := conn.GetTime(clean(p)); err == nil {
+			info.ModTime = modTime
+		}
+	}
+
+	return info, nil
+}
+
+func (l *ftpDisk) entryWithLock(conn *ftp.ServerConn, p string) (*ftp.Entry, error) {
+	var protocolError *textproto.Error
+
+	entry, err := conn.GetEntry(clean(p))
+	if err == nil {
+		return entry, nil
+	}
+
+	if !errors.As(err, &protocolError) {
+		return nil, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	switch protocolError.Code {
+	case ftp.StatusFileUnavailable:
+		return nil, fmt.Errorf("failed to get path info: %s: %w", clean(p), os.ErrNotExist)
+	case ftp.StatusNotImplemented:
+		// MLST is not supported by the server, look for the path in the listing of its parent
+	default:
+		return nil, fmt.Errorf("failed to get path info: %w", err)
+	}
+
+	if clean(p) == "/" {
+		return &ftp.Entry{Name: "/", Type: ftp.EntryTypeFolder}, nil
+	}
+
+	entries, err := conn.List(path.Dir(clean(p)))
+	if err != nil {
+		if errors.As(err, &protocolError) && protocolError.Code == ftp.StatusFileUnavailable {
+			return nil, fmt.Errorf("failed to get path info: %s: %w", clean(p), os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to list parent path: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name == path.Base(clean(p)) {
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get path info: %s: %w", clean(p), os.ErrNotExist)
+}
+
+func (l *ftpDisk) Rename(ctx context.Context, from string, to string) (err error) {
+	conn, err := l.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		conn.done(err)
+	}()
+
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "ftp"))
+
+	if err := conn.Value().Rename(clean(from), clean(to)); err != nil {
+		// Some servers refuse to replace an existing file
+		if deleteErr := conn.Value().Delete(clean(to)); deleteErr != nil {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+
+		if err := conn.Value().Rename(clean(from), clean(to)); err != nil {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *ftpDisk) Chmod(_ context.Context, _ string, _ os.FileMode) error {
+	// SITE CHMOD is not exposed by the ftp client
+	return fmt.Errorf("failed to change permissions: %w", errors.ErrUnsupported)
+}
+
+func (l *ftpDisk) goHome(conn *ftp.ServerConn) error {
 	slog.Debug("going to root directory", slog.String("schema", "ftp"))
 
-	err := res.Value().ChangeDir("/")
+	err := conn.ChangeDir("/")
 	if err != nil {
 		return fmt.Errorf("failed to change directory: %w", err)
 	}
@@ -367,15 +607,99 @@ func (l *ftpDisk) goHome(res *puddle.Resource[*ftp.ServerConn]) error {
 	return nil
 }
 
-func (l *ftpDisk) acquire() (*puddle.Resource[*ftp.ServerConn], error) {
-	res, err := l.pool.Acquire(context.TODO())
-	if err != nil {
-		return nil, fmt.Errorf("failed acquiring connection: %w", err)
+// ftpConnection is a connection taken from the pool
+type ftpConnection struct {
+	*puddle.Resource[*ftp.ServerConn]
+	stop func() bool
+}
+
+// done gives the connection back to the pool. The connection is destroyed instead
+// if its context was cancelled while in use, or if err shows it is no longer usable.
+func (c *ftpConnection) done(err error) {
+	if !c.stop() || isConnectionError(err) {
+		c.Destroy()
+		return
 	}
 
-	if err := l.goHome(res); err != nil {
-		return nil, err
+	c.Release()
+}
+
+// acquire takes a connection from the pool.
+// If the context is cancelled while the connection is in use, the connection is closed
+// to abort the running command.
+//
+// Idle connections might have been closed by the server in the meantime,
+// these are replaced until a working connection is found.
+func (l *ftpDisk) acquire(ctx context.Context) (*ftpConnection, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := l.pool.Acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed acquiring connection: %w", err)
+		}
+
+		conn := &ftpConnection{
+			Resource: res,
+			stop: context.AfterFunc(ctx, func() {
+				_ = res.Value().Quit()
+			}),
+		}
+
+		err = l.goHome(res.Value())
+		if err == nil {
+			return conn, nil
+		}
+
+		// The connection is unusable, do not return it to the pool
+		conn.stop()
+		conn.Destroy()
+
+		if !isConnectionError(err) || ctx.Err() != nil || attempt >= int(l.pool.Stat().MaxResources()) {
+			return nil, err
+		}
+
+		slog.Warn("replacing dead ftp connection", slog.Any("err", err))
+	}
+}
+
+// withRetry runs an idempotent operation on a connection from the pool.
+// If the connection is lost during the operation, it is run again on a new connection.
+func (l *ftpDisk) withRetry(ctx context.Context, operation func(conn *ftp.ServerConn) error) error {
+	for attempt := 0; ; attempt++ {
+		conn, err := l.acquire(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = operation(conn.Value())
+		conn.done(err)
+
+		if err == nil || !isConnectionError(err) || ctx.Err() != nil || attempt >= ftpRetries {
+			return err
+		}
+
+		slog.Warn("ftp connection lost, retrying", slog.Int("attempt", attempt+1), slog.Any("err", err))
 	}
+}
 
-	return res, nil
+// isConnectionError checks if the error was caused by a broken control connection,
+// as opposed to the server rejecting a command
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var protocolError *textproto.Error
+	if errors.As(err, &protocolError) {
+		return protocolError.Code == ftp.StatusNotAvailable
+	}
+
+	var malformedResponse textproto.ProtocolError
+	var netError net.Error
+	return errors.As(err, &malformedResponse) ||
+		errors.As(err, &netError) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET)
 }
@@ -0,0 +1,182 @@
+package disk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"goftp.io/server/v2"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk/disktest"
+)
+
+// writeCertificate creates a self-signed certificate for 127.0.0.1,
+// and returns the paths of the certificate and key, and the fingerprint of the certificate
+func writeCertificate(t *testing.T) (string, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testza.AssertNoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	testza.AssertNoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	testza.AssertNoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	testza.AssertNoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	testza.AssertNoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+
+	fingerprint := sha256.Sum256(der)
+
+	return certFile, keyFile, hex.EncodeToString(fingerprint[:])
+}
+
+func TestFTPSDisk(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	certFile, keyFile, fingerprint := writeCertificate(t)
+
+	// The test server does not accept PBSZ on implicit TLS sessions, so only explicit TLS is covered
+	address := disktest.StartFTPServer(t, t.TempDir(), func(options *server.Options) {
+		options.TLS = true
+		options.ExplicitFTPS = true
+		options.ForceTLS = true
+		options.CertFile = certFile
+		options.KeyFile = keyFile
+	})
+
+	d, err := FromPath("ftps://user:pass@" + address + "/?fingerprint=" + fingerprint)
+	testza.AssertNoError(t, err)
+
+	testDiskConformance(t, d, "/")
+}
+
+func TestFTPSRejectsCertificate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	certFile, keyFile, _ := writeCertificate(t)
+
+	address := disktest.StartFTPServer(t, t.TempDir(), func(options *server.Options) {
+		options.TLS = true
+		options.ExplicitFTPS = true
+		options.CertFile = certFile
+		options.KeyFile = keyFile
+	})
+
+	ctx := context.Background()
+
+	// Self-signed certificates are not trusted without a fingerprint
+	d, err := FromPath("ftps://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+	_, err = d.Exists(ctx, "/")
+	testza.AssertNotNil(t, err)
+
+	d, err = FromPath("ftps://user:pass@" + address + "/?fingerprint=" + hex.EncodeToString(make([]byte, sha256.Size)))
+	testza.AssertNoError(t, err)
+	_, err = d.Exists(ctx, "/")
+	testza.AssertNotNil(t, err)
+
+	d, err = FromPath("ftps://user:pass@" + address + "/?skip-verify=true")
+	testza.AssertNoError(t, err)
+	_, err = d.Exists(ctx, "/")
+	testza.AssertNoError(t, err)
+
+	_, err = FromPath("ftps://user:pass@" + address + "/?tls=opportunistic")
+	testza.AssertNotNil(t, err)
+}
+
+func TestFTPReplacesDeadConnections(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	ctx := context.Background()
+	address := disktest.StartFTPServer(t, t.TempDir(), nil)
+
+	d, err := FromPath("ftp://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+
+	testza.AssertNoError(t, d.Write(ctx, "/test.txt", []byte("test")))
+
+	// Simulate the server closing the idle connections
+	pool := d.(*ftpDisk).pool
+	idle := pool.AcquireAllIdle()
+	testza.AssertGreater(t, len(idle), 0)
+	for _, res := range idle {
+		_ = res.Value().Quit()
+		res.Release()
+	}
+
+	exists, err := d.Exists(ctx, "/test.txt")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	data, err := d.Read(ctx, "/test.txt")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []byte("test"), data)
+}
+
+func TestFTPOpenReturnsStoreError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	ctx := context.Background()
+	address := disktest.StartFTPServer(t, t.TempDir(), nil)
+
+	d, err := FromPath("ftp://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+
+	// The server cannot store files in missing directories
+	w, err := d.Open(ctx, "/missing/test.txt", os.O_CREATE|os.O_RDWR)
+	testza.AssertNoError(t, err)
+	_, _ = w.Write([]byte("test"))
+	testza.AssertNotNil(t, w.Close())
+
+	w, err = d.Open(ctx, "/test.txt", os.O_CREATE|os.O_RDWR)
+	testza.AssertNoError(t, err)
+	_, err = w.Write([]byte("test"))
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+
+	data, err := d.Read(ctx, "/test.txt")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []byte("test"), data)
+}
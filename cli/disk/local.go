@@ -1,6 +1,7 @@
 package disk
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -21,7 +22,11 @@ func newLocal(path string) (Disk, error) {
 	return localDisk{path: path}, nil
 }
 
-func (l localDisk) Exists(path string) (bool, error) {
+func (l localDisk) Exists(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err //nolint:wrapcheck
+	}
+
 	_, err := os.Stat(path)
 
 	if errors.Is(err, os.ErrNotExist) {
@@ -35,23 +40,43 @@ func (l localDisk) Exists(path string) (bool, error) {
 	return true, nil
 }
 
-func (l localDisk) Read(path string) ([]byte, error) {
+func (l localDisk) Read(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	return os.ReadFile(path) //nolint
 }
 
-func (l localDisk) Write(path string, data []byte) error {
+func (l localDisk) Write(ctx context.Context, path string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	return os.WriteFile(path, data, 0o777) //nolint
 }
 
-func (l localDisk) Remove(path string) error {
+func (l localDisk) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	return os.RemoveAll(path) //nolint
 }
 
-func (l localDisk) MkDir(path string) error {
+func (l localDisk) MkDir(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	return os.MkdirAll(path, 0o777) //nolint
 }
 
-func (l localDisk) ReadDir(path string) ([]Entry, error) {
+func (l localDisk) ReadDir(ctx context.Context, path string) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	dir, err := os.ReadDir(path)
 	if err != nil {
 		return nil, err //nolint
@@ -67,6 +92,56 @@ func (l localDisk) ReadDir(path string) ([]Entry, error) {
 	return entries, nil
 }
 
-func (l localDisk) Open(path string, flag int) (io.WriteCloser, error) {
-	return os.OpenFile(path, flag, 0o777) //nolint
+func (l localDisk) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return os.Open(path) //nolint
+}
+
+func (l localDisk) Stat(ctx context.Context, path string) (FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return FileInfo{}, err //nolint:wrapcheck
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err //nolint:wrapcheck
+	}
+
+	return FileInfo{
+		ModTime: stat.ModTime(),
+		Size:    stat.Size(),
+		IsDir:   stat.IsDir(),
+	}, nil
+}
+
+func (l localDisk) Rename(ctx context.Context, from string, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return os.Rename(from, to) //nolint
+}
+
+func (l localDisk) Chmod(ctx context.Context, path string, mode os.FileMode) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return os.Chmod(path, mode) //nolint
+}
+
+func (l localDisk) Open(ctx context.Context, path string, flag int) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	f, err := os.OpenFile(path, flag, 0o777)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return contextWriter{ctx: ctx, WriteCloser: f}, nil
 }
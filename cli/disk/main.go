@@ -1,38 +1,94 @@
 package disk
 
 import (
+	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/url"
+	"os"
 	"path/filepath"
+	"time"
 )
 
 type Disk interface {
 	// Exists checks if the provided file or directory exists
-	Exists(path string) (bool, error)
+	Exists(ctx context.Context, path string) (bool, error)
 
 	// Read returns the entire file as a byte buffer
 	//
 	// Returns error if provided path is not a file
-	Read(path string) ([]byte, error)
+	Read(ctx context.Context, path string) ([]byte, error)
 
 	// Write writes provided byte buffer to the path
-	Write(path string, data []byte) error
+	Write(ctx context.Context, path string, data []byte) error
 
 	// Remove deletes the provided file or directory recursively
-	Remove(path string) error
+	Remove(ctx context.Context, path string) error
 
 	// MkDir creates the provided directory recursively
-	MkDir(path string) error
+	MkDir(ctx context.Context, path string) error
 
 	// ReadDir returns all entries within the directory
 	//
 	// Returns error if provided path is not a directory
-	ReadDir(path string) ([]Entry, error)
+	ReadDir(ctx context.Context, path string) ([]Entry, error)
 
 	// Open opens provided path for writing
-	Open(path string, flag int) (io.WriteCloser, error)
+	//
+	// Writes fail once the context is cancelled
+	Open(ctx context.Context, path string, flag int) (io.WriteCloser, error)
+
+	// OpenReader opens provided path for reading
+	//
+	// Returns error if provided path is not a file
+	OpenReader(ctx context.Context, path string) (io.ReadCloser, error)
+
+	// Stat returns information about the provided file or directory
+	//
+	// Returns an error matching os.ErrNotExist if the path does not exist
+	Stat(ctx context.Context, path string) (FileInfo, error)
+
+	// Rename moves the provided file or directory, replacing the destination file if it exists
+	Rename(ctx context.Context, from string, to string) error
+
+	// Chmod changes the permissions of the provided path
+	//
+	// Returns an error matching errors.ErrUnsupported if the backend has no permissions
+	Chmod(ctx context.Context, path string, mode os.FileMode) error
+}
+
+// Extractor is implemented by disks which can extract zip archives on their side,
+// instead of receiving every file of the archive separately
+type Extractor interface {
+	// ExtractZip uploads the archive, extracts it into the provided directory,
+	// and checks the extracted files against the files of the archive
+	//
+	// Returns an error matching errors.ErrUnsupported if the disk cannot extract archives
+	ExtractZip(ctx context.Context, archive io.Reader, files []*zip.File, location string) error
+}
+
+// Runner is implemented by disks which can run commands on their side
+type Runner interface {
+	// Run runs the command in a POSIX shell with the environment variables set,
+	// and returns its combined output. Returns an error if the command exits with a non-zero code.
+	Run(ctx context.Context, command string, env map[string]string) ([]byte, error)
+}
+
+// ExitError is returned by Runner when the command exits with a non-zero code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+type FileInfo struct {
+	ModTime time.Time
+	Size    int64
+	IsDir   bool
 }
 
 type Entry interface {
@@ -40,6 +96,32 @@ type Entry interface {
 	Name() string
 }
 
+// contextWriter fails writes once its context is cancelled
+type contextWriter struct {
+	ctx context.Context
+	io.WriteCloser
+}
+
+func (w contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+	return w.WriteCloser.Write(p) //nolint:wrapcheck
+}
+
+// contextReader fails reads once its context is cancelled
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+	return r.Reader.Read(p) //nolint:wrapcheck
+}
+
 func FromPath(path string) (Disk, error) {
 	parsed, err := url.Parse(path)
 	if err != nil {
@@ -47,12 +129,21 @@ func FromPath(path string) (Disk, error) {
 	}
 
 	switch parsed.Scheme {
-	case "ftp":
-		slog.Info("connecting to ftp")
+	case "ftp", "ftps":
+		slog.Info("connecting to ftp", slog.Bool("tls", parsed.Scheme == "ftps"))
 		return newFTP(path)
 	case "sftp":
 		slog.Info("connecting to sftp")
 		return newSFTP(path)
+	case "docker":
+		slog.Info("connecting to docker")
+		return newDocker(path)
+	case "webdav", "webdavs":
+		slog.Info("connecting to webdav", slog.Bool("tls", parsed.Scheme == "webdavs"))
+		return newWebDAV(path)
+	case "memory":
+		slog.Info("using memory disk", slog.String("name", parsed.Host))
+		return newMemory(path)
 	}
 
 	slog.Info("using local disk", slog.String("path", path))
@@ -0,0 +1,424 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+var _ Disk = (*Memory)(nil)
+
+// memoryDisks holds the disks of memory:// paths by name,
+// so that every path with the same name shares its files within the process
+var memoryDisks = xsync.NewMapOf[string, *Memory]()
+
+// Memory is a disk which only keeps its files in memory.
+// It behaves like the local disk, and is used to test installations
+// and to simulate changes without touching a real installation.
+type Memory struct {
+	files map[string]*memoryFile
+	mu    sync.RWMutex
+}
+
+type memoryFile struct {
+	modTime time.Time
+	data    []byte
+	mode    os.FileMode
+	isDir   bool
+}
+
+type memoryEntry struct {
+	name  string
+	isDir bool
+}
+
+func (f memoryEntry) IsDir() bool {
+	return f.isDir
+}
+
+func (f memoryEntry) Name() string {
+	return f.name
+}
+
+// NewMemory returns an empty memory disk
+func NewMemory() *Memory {
+	return &Memory{
+		files: make(map[string]*memoryFile),
+	}
+}
+
+func newMemory(path string) (Disk, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse memory url: %w", err)
+	}
+
+	d, _ := memoryDisks.LoadOrCompute(u.Host, NewMemory)
+
+	return d, nil
+}
+
+// RemoveMemory drops the disk of the memory:// paths with the given name along with its files.
+// Later paths with the same name get a new empty disk.
+func RemoveMemory(name string) {
+	memoryDisks.Delete(name)
+}
+
+// isMemoryRoot checks if the path is the root of the disk, which always exists
+func isMemoryRoot(p string) bool {
+	return path.Dir(p) == p
+}
+
+// get returns the file or directory at the path. Must be called with the lock held.
+func (m *Memory) get(p string) (*memoryFile, bool) {
+	if isMemoryRoot(p) {
+		return &memoryFile{isDir: true, mode: os.ModeDir | 0o777}, true
+	}
+
+	f, ok := m.files[p]
+	return f, ok
+}
+
+// checkParent returns an error if the parent of the path is not a directory. Must be called with the lock held.
+func (m *Memory) checkParent(op string, p string) error {
+	parent, ok := m.get(path.Dir(p))
+	if !ok {
+		return &fs.PathError{Op: op, Path: p, Err: os.ErrNotExist}
+	}
+
+	if !parent.isDir {
+		return &fs.PathError{Op: op, Path: p, Err: errors.New("not a directory")}
+	}
+
+	return nil
+}
+
+func (m *Memory) Exists(ctx context.Context, p string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err //nolint:wrapcheck
+	}
+
+	slog.Debug("checking if file exists", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.get(clean(p))
+	return ok, nil
+}
+
+func (m *Memory) Read(ctx context.Context, p string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("reading file", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.get(clean(p))
+	if !ok {
+		return nil, fmt.Errorf("failed to read file: %w", &fs.PathError{Op: "open", Path: clean(p), Err: os.ErrNotExist})
+	}
+
+	if f.isDir {
+		return nil, fmt.Errorf("failed to read file: %s is a directory", clean(p))
+	}
+
+	return slices.Clone(f.data), nil
+}
+
+func (m *Memory) Write(ctx context.Context, p string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("writing to file", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkParent("open", clean(p)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	mode := os.FileMode(0o666)
+	if f, ok := m.get(clean(p)); ok {
+		if f.isDir {
+			return fmt.Errorf("failed to write file: %s is a directory", clean(p))
+		}
+		mode = f.mode
+	}
+
+	m.files[clean(p)] = &memoryFile{
+		data:    slices.Clone(data),
+		modTime: time.Now(),
+		mode:    mode,
+	}
+
+	return nil
+}
+
+func (m *Memory) Remove(ctx context.Context, p string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("deleting path", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(clean(p))
+
+	return nil
+}
+
+// removeLocked removes the path and everything within it. Must be called with the lock held.
+func (m *Memory) removeLocked(p string) {
+	delete(m.files, p)
+
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for name := range m.files {
+		if strings.HasPrefix(name, prefix) {
+			delete(m.files, name)
+		}
+	}
+}
+
+func (m *Memory) MkDir(ctx context.Context, p string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("making directory", slog.String("dir", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var missing []string
+	for current := clean(p); ; current = path.Dir(current) {
+		f, ok := m.get(current)
+		if ok {
+			if !f.isDir {
+				return fmt.Errorf("failed to make directory: %s is not a directory", current)
+			}
+			break
+		}
+		missing = append(missing, current)
+	}
+
+	for _, dir := range missing {
+		m.files[dir] = &memoryFile{
+			modTime: time.Now(),
+			mode:    os.ModeDir | 0o777,
+			isDir:   true,
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) ReadDir(ctx context.Context, p string) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("reading directory", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dir, ok := m.get(clean(p))
+	if !ok {
+		return nil, fmt.Errorf("failed to read directory: %w", &fs.PathError{Op: "open", Path: clean(p), Err: os.ErrNotExist})
+	}
+
+	if !dir.isDir {
+		return nil, fmt.Errorf("failed to read directory: %s is not a directory", clean(p))
+	}
+
+	var entries []Entry
+	for name, f := range m.files {
+		if name != clean(p) && path.Dir(name) == clean(p) {
+			entries = append(entries, memoryEntry{
+				name:  path.Base(name),
+				isDir: f.isDir,
+			})
+		}
+	}
+
+	// Same order as the local disk
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// memoryWriter writes to a file of a memory disk at its current offset
+type memoryWriter struct {
+	disk   *Memory
+	path   string
+	offset int
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	w.disk.mu.Lock()
+	defer w.disk.mu.Unlock()
+
+	f, ok := w.disk.files[w.path]
+	if !ok {
+		return 0, &fs.PathError{Op: "write", Path: w.path, Err: os.ErrNotExist}
+	}
+
+	if end := w.offset + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+
+	copy(f.data[w.offset:], p)
+	w.offset += len(p)
+	f.modTime = time.Now()
+
+	return len(p), nil
+}
+
+func (w *memoryWriter) Close() error {
+	return nil
+}
+
+func (m *Memory) Open(ctx context.Context, p string, flag int) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("opening for writing", slog.String("path", clean(p)), slog.String("schema", "memory"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkParent("open", clean(p)); err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	f, ok := m.get(clean(p))
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, fmt.Errorf("failed to open file: %w", &fs.PathError{Op: "open", Path: clean(p), Err: os.ErrNotExist})
+	case !ok:
+		m.files[clean(p)] = &memoryFile{
+			modTime: time.Now(),
+			mode:    0o666,
+		}
+	case f.isDir:
+		return nil, fmt.Errorf("failed to open file: %s is a directory", clean(p))
+	case flag&os.O_TRUNC != 0:
+		f.data = nil
+		f.modTime = time.Now()
+	}
+
+	return contextWriter{ctx: ctx, WriteCloser: &memoryWriter{
+		disk: m,
+		path: clean(p),
+	}}, nil
+}
+
+func (m *Memory) OpenReader(ctx context.Context, p string) (io.ReadCloser, error) {
+	data, err := m.Read(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(contextReader{ctx: ctx, Reader: bytes.NewReader(data)}), nil
+}
+
+func (m *Memory) Stat(ctx context.Context, p string) (FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return FileInfo{}, err //nolint:wrapcheck
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.get(clean(p))
+	if !ok {
+		return FileInfo{}, fmt.Errorf("failed to stat path: %w", &fs.PathError{Op: "stat", Path: clean(p), Err: os.ErrNotExist})
+	}
+
+	return FileInfo{
+		ModTime: f.modTime,
+		Size:    int64(len(f.data)),
+		IsDir:   f.isDir,
+	}, nil
+}
+
+func (m *Memory) Rename(ctx context.Context, from string, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "memory"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.get(clean(from))
+	if !ok || isMemoryRoot(clean(from)) {
+		return fmt.Errorf("failed to rename path: %w", &fs.PathError{Op: "rename", Path: clean(from), Err: os.ErrNotExist})
+	}
+
+	if err := m.checkParent("rename", clean(to)); err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+
+	if target, ok := m.get(clean(to)); ok && target.isDir != source.isDir {
+		return fmt.Errorf("failed to rename path: cannot replace %s", clean(to))
+	}
+
+	m.removeLocked(clean(to))
+
+	prefix := clean(from) + "/"
+	for name, f := range m.files {
+		if name == clean(from) {
+			m.files[clean(to)] = f
+			delete(m.files, name)
+		} else if rest, ok := strings.CutPrefix(name, prefix); ok {
+			m.files[path.Join(clean(to), rest)] = f
+			delete(m.files, name)
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) Chmod(ctx context.Context, p string, mode os.FileMode) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[clean(p)]
+	if !ok {
+		return fmt.Errorf("failed to change permissions: %w", &fs.PathError{Op: "chmod", Path: clean(p), Err: os.ErrNotExist})
+	}
+
+	f.mode = f.mode.Type() | mode.Perm()
+
+	return nil
+}
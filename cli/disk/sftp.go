@@ -1,23 +1,32 @@
 package disk
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/url"
 	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/pkg/sftp"
+	"github.com/spf13/viper"
 	"golang.org/x/crypto/ssh"
 )
 
 var _ Disk = (*sftpDisk)(nil)
 
 type sftpDisk struct {
-	client *sftp.Client
-	path   string
+	client         *sftp.Client
+	conn           *ssh.Client
+	unzipAvailable func() bool
+	path           string
 }
 
 type sftpEntry struct {
@@ -45,8 +54,9 @@ func newSFTP(path string) (Disk, error) {
 	}
 
 	conn, err := ssh.Dial("tcp", u.Host, &ssh.ClientConfig{
-		User: u.User.Username(),
-		Auth: auth,
+		User:    u.User.Username(),
+		Auth:    auth,
+		Timeout: viper.GetDuration("connect-timeout"),
 
 		// TODO Somehow use systems hosts file
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
@@ -63,12 +73,18 @@ func newSFTP(path string) (Disk, error) {
 	slog.Info("logged into sftp")
 
 	return sftpDisk{
-		path:   path,
-		client: client,
+		path:           path,
+		client:         client,
+		conn:           conn,
+		unzipAvailable: sync.OnceValue(func() bool { return checkUnzip(conn) }),
 	}, nil
 }
 
-func (l sftpDisk) Exists(path string) (bool, error) {
+func (l sftpDisk) Exists(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err //nolint:wrapcheck
+	}
+
 	slog.Debug("checking if file exists", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	s, err := l.client.Stat(clean(path))
@@ -83,7 +99,11 @@ func (l sftpDisk) Exists(path string) (bool, error) {
 	return s != nil, nil
 }
 
-func (l sftpDisk) Read(path string) ([]byte, error) {
+func (l sftpDisk) Read(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	f, err := l.client.Open(clean(path))
@@ -101,7 +121,11 @@ func (l sftpDisk) Read(path string) ([]byte, error) {
 	return data, nil
 }
 
-func (l sftpDisk) Write(path string, data []byte) error {
+func (l sftpDisk) Write(ctx context.Context, path string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	file, err := l.client.Create(clean(path))
@@ -111,14 +135,18 @@ func (l sftpDisk) Write(path string, data []byte) error {
 
 	defer file.Close()
 
-	if _, err = io.Copy(file, bytes.NewReader(data)); err != nil {
+	if _, err = io.Copy(contextWriter{ctx: ctx, WriteCloser: file}, bytes.NewReader(data)); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
-func (l sftpDisk) Remove(path string) error {
+func (l sftpDisk) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 	if err := l.client.Remove(clean(path)); err != nil {
 		if err := l.client.RemoveAll(clean(path)); err != nil {
@@ -129,7 +157,11 @@ func (l sftpDisk) Remove(path string) error {
 	return nil
 }
 
-func (l sftpDisk) MkDir(path string) error {
+func (l sftpDisk) MkDir(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	slog.Debug("making directory", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	if err := l.client.MkdirAll(clean(path)); err != nil {
@@ -139,7 +171,11 @@ func (l sftpDisk) MkDir(path string) error {
 	return nil
 }
 
-func (l sftpDisk) ReadDir(path string) ([]Entry, error) {
+func (l sftpDisk) ReadDir(ctx context.Context, path string) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	slog.Debug("reading directory", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	dir, err := l.client.ReadDir(clean(path))
@@ -157,13 +193,255 @@ func (l sftpDisk) ReadDir(path string) ([]Entry, error) {
 	return entries, nil
 }
 
-func (l sftpDisk) Open(path string, _ int) (io.WriteCloser, error) {
+func (l sftpDisk) Open(ctx context.Context, path string, _ int) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "sftp"))
 
 	f, err := l.client.Create(clean(path))
 	if err != nil {
-		slog.Error("failed to open file", slog.Any("err", err))
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return contextWriter{ctx: ctx, WriteCloser: f}, nil
+}
+
+func (l sftpDisk) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("opening for reading", slog.String("path", clean(path)), slog.String("schema", "sftp"))
+
+	f, err := l.client.Open(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	return f, nil
 }
+
+func (l sftpDisk) Stat(ctx context.Context, path string) (FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return FileInfo{}, err //nolint:wrapcheck
+	}
+
+	slog.Debug("getting file info", slog.String("path", clean(path)), slog.String("schema", "sftp"))
+
+	s, err := l.client.Stat(clean(path))
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return FileInfo{
+		ModTime: s.ModTime(),
+		Size:    s.Size(),
+		IsDir:   s.IsDir(),
+	}, nil
+}
+
+func (l sftpDisk) Rename(ctx context.Context, from string, to string) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "sftp"))
+
+	// Plain SFTP renames fail if the destination exists, the posix-rename extension replaces it
+	if err := l.client.PosixRename(clean(from), clean(to)); err != nil {
+		if err := l.client.Rename(clean(from), clean(to)); err != nil {
+			return fmt.Errorf("failed to rename path: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l sftpDisk) Chmod(ctx context.Context, path string, mode os.FileMode) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	slog.Debug("changing permissions", slog.String("path", clean(path)), slog.String("mode", mode.String()), slog.String("schema", "sftp"))
+
+	if err := l.client.Chmod(clean(path), mode); err != nil {
+		return fmt.Errorf("failed to change permissions: %w", err)
+	}
+
+	return nil
+}
+
+var _ Extractor = (*sftpDisk)(nil)
+
+// checkUnzip returns whether unzip can be executed on the server
+func checkUnzip(conn *ssh.Client) bool {
+	session, err := conn.NewSession()
+	if err != nil {
+		slog.Info("ssh exec is not available, extracting mods locally", slog.Any("err", err))
+		return false
+	}
+	defer session.Close()
+
+	if err := session.Run("command -v unzip"); err != nil {
+		slog.Info("unzip is not available on the server, extracting mods locally", slog.Any("err", err))
+		return false
+	}
+
+	return true
+}
+
+func (l sftpDisk) ExtractZip(ctx context.Context, archive io.Reader, files []*zip.File, location string) error {
+	if !l.unzipAvailable() {
+		return fmt.Errorf("failed to extract remotely: %w", errors.ErrUnsupported)
+	}
+
+	if err := l.MkDir(ctx, location); err != nil {
+		return err
+	}
+
+	// The archive is uploaded next to the extraction directory, so it is not part of the extracted files
+	uploadPath := path.Join(path.Dir(clean(location)), "."+path.Base(clean(location))+".zip")
+
+	slog.Debug("uploading archive", slog.String("path", uploadPath), slog.String("schema", "sftp"))
+
+	upload, err := l.client.Create(uploadPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	defer func() {
+		if err := l.client.Remove(uploadPath); err != nil {
+			slog.Warn("failed to remove uploaded archive", slog.String("path", uploadPath), slog.Any("err", err))
+		}
+	}()
+
+	if _, err := upload.ReadFrom(contextReader{ctx: ctx, Reader: archive}); err != nil {
+		upload.Close()
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	if err := upload.Close(); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	slog.Debug("extracting archive", slog.String("path", uploadPath), slog.String("location", clean(location)), slog.String("schema", "sftp"))
+
+	if err := l.run(ctx, "unzip -o -q "+shellQuote(uploadPath)+" -d "+shellQuote(clean(location))); err != nil {
+		return fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	return l.verifyExtracted(files, location)
+}
+
+// verifyExtracted checks that every file of the archive was extracted with the right size
+func (l sftpDisk) verifyExtracted(files []*zip.File, location string) error {
+	extracted := make(map[string]int64)
+
+	walker := l.client.Walk(clean(location))
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return fmt.Errorf("failed to list extracted files: %w", err)
+		}
+
+		if walker.Stat().IsDir() {
+			continue
+		}
+
+		extracted[strings.TrimPrefix(walker.Path(), clean(location)+"/")] = walker.Stat().Size()
+	}
+
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		size, ok := extracted[path.Clean(file.Name)]
+		if !ok {
+			return fmt.Errorf("extracted archive is missing %s", file.Name)
+		}
+
+		if size != int64(file.UncompressedSize64) {
+			return fmt.Errorf("extracted %s has size %d instead of %d", file.Name, size, file.UncompressedSize64)
+		}
+	}
+
+	return nil
+}
+
+// run executes the command on the server, stopping it if the context is cancelled
+func (l sftpDisk) run(ctx context.Context, command string) error {
+	output, err := l.output(ctx, command)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr //nolint:wrapcheck
+		}
+		return fmt.Errorf("command failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// output executes the command on the server and returns its combined output,
+// stopping it if the context is cancelled
+func (l sftpDisk) output(ctx context.Context, command string) ([]byte, error) {
+	session, err := l.conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	defer session.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = session.Close()
+	})
+	defer stop()
+
+	output, err := session.CombinedOutput(command)
+	if err != nil {
+		return output, err //nolint:wrapcheck
+	}
+
+	return output, nil
+}
+
+var _ Runner = (*sftpDisk)(nil)
+
+func (l sftpDisk) Run(ctx context.Context, command string, env map[string]string) ([]byte, error) {
+	slog.Debug("running command", slog.String("command", command), slog.String("schema", "sftp"))
+
+	// Servers usually do not accept environment variables from clients, so they are exported by the script.
+	// sh is used explicitly, as the login shell of the user may not be a POSIX shell.
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var script strings.Builder
+	for _, key := range keys {
+		script.WriteString("export " + key + "=" + shellQuote(env[key]) + "\n")
+	}
+	script.WriteString(command)
+
+	output, err := l.output(ctx, "sh -c "+shellQuote(script.String()))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return output, ctxErr //nolint:wrapcheck
+		}
+
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			return output, &ExitError{Code: exitErr.ExitStatus()}
+		}
+
+		return output, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return output, nil
+}
+
+// shellQuote quotes the argument for a POSIX shell
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,121 @@
+package disk
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk/disktest"
+)
+
+func testZip(t *testing.T, files map[string]string) ([]byte, *zip.Reader) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		testza.AssertNoError(t, err)
+		_, err = w.Write([]byte(content))
+		testza.AssertNoError(t, err)
+	}
+	testza.AssertNoError(t, writer.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	testza.AssertNoError(t, err)
+
+	return buf.Bytes(), reader
+}
+
+func TestSFTPExtractZip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	if _, err := exec.LookPath("unzip"); err != nil {
+		t.Skip("unzip is not installed")
+	}
+
+	root := t.TempDir()
+	address := disktest.StartSFTPServer(t, "user", "pass", true)
+
+	d, err := FromPath("sftp://user:pass@" + address + root)
+	testza.AssertNoError(t, err)
+
+	extractor, ok := d.(Extractor)
+	testza.AssertTrue(t, ok)
+
+	archive, reader := testZip(t, map[string]string{
+		"Mod.uplugin":            `{"SemVersion": "1.0.0"}`,
+		"Content/Paks/Mod.pak":   "pak",
+		"Binaries/Win64/Mod.dll": "dll",
+	})
+
+	location := filepath.Join(root, "Mod")
+	testza.AssertNoError(t, extractor.ExtractZip(context.Background(), bytes.NewReader(archive), reader.File, location))
+
+	data, err := os.ReadFile(filepath.Join(location, "Content", "Paks", "Mod.pak"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []byte("pak"), data)
+
+	// Only the extracted mod is left behind
+	entries, err := os.ReadDir(root)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, entries, 1)
+	testza.AssertEqual(t, "Mod", entries[0].Name())
+}
+
+func TestSFTPExtractZipWithoutExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	root := t.TempDir()
+	address := disktest.StartSFTPServer(t, "user", "pass", false)
+
+	d, err := FromPath("sftp://user:pass@" + address + root)
+	testza.AssertNoError(t, err)
+
+	archive, reader := testZip(t, map[string]string{
+		"Mod.uplugin": `{"SemVersion": "1.0.0"}`,
+	})
+
+	err = d.(Extractor).ExtractZip(context.Background(), bytes.NewReader(archive), reader.File, filepath.Join(root, "Mod"))
+	testza.AssertTrue(t, errors.Is(err, errors.ErrUnsupported), err)
+}
+
+func TestSFTPRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+	address := disktest.StartSFTPServer(t, "user", "pass", true)
+
+	d, err := FromPath("sftp://user:pass@" + address + root)
+	testza.AssertNoError(t, err)
+
+	runner, ok := d.(Runner)
+	testza.AssertTrue(t, ok)
+
+	output, err := runner.Run(ctx, `echo "$GREETING"`, map[string]string{"GREETING": "it's a 'test'"})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "it's a 'test'\n", string(output))
+
+	output, err = runner.Run(ctx, "echo failing; exit 3", nil)
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, err.Error(), "exited with code 3")
+	testza.AssertEqual(t, "failing\n", string(output))
+}
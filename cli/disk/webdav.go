@@ -0,0 +1,388 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/studio-b12/gowebdav"
+)
+
+var _ Disk = (*webdavDisk)(nil)
+
+type webdavDisk struct {
+	// client is only used for PROPFIND requests, as it cannot cancel requests or stream uploads
+	client *gowebdav.Client
+	auth   gowebdav.Authorizer
+	http   *http.Client
+	root   string
+	path   string
+}
+
+type webdavEntry struct {
+	os.FileInfo
+}
+
+func (f webdavEntry) IsDir() bool {
+	return f.FileInfo.IsDir()
+}
+
+func (f webdavEntry) Name() string {
+	return f.FileInfo.Name()
+}
+
+func newWebDAV(path string) (Disk, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webdav url: %w", err)
+	}
+
+	scheme := "http"
+	if u.Scheme == "webdavs" {
+		scheme = "https"
+	}
+
+	root := (&url.URL{Scheme: scheme, Host: u.Host}).String()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: viper.GetDuration("connect-timeout")}).DialContext
+	transport.TLSHandshakeTimeout = viper.GetDuration("connect-timeout")
+	transport.ResponseHeaderTimeout = viper.GetDuration("read-timeout")
+
+	password, _ := u.User.Password()
+	auth := gowebdav.NewAutoAuth(u.User.Username(), password)
+
+	client := gowebdav.NewAuthClient(root, auth)
+	client.SetTransport(transport)
+
+	d := &webdavDisk{
+		client: client,
+		auth:   auth,
+		http:   &http.Client{Transport: transport},
+		root:   root,
+		path:   u.Path,
+	}
+
+	// Negotiates the authentication method (basic or digest) up front,
+	// as streamed uploads cannot be sent again once the server asks for authentication
+	if _, err := d.Stat(context.Background(), u.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to connect to webdav server: %w", err)
+	}
+
+	slog.Info("logged into webdav")
+
+	return d, nil
+}
+
+func (l *webdavDisk) Exists(ctx context.Context, path string) (bool, error) {
+	slog.Debug("checking if file exists", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	_, err := l.Stat(ctx, path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *webdavDisk) Read(ctx context.Context, path string) ([]byte, error) {
+	slog.Debug("reading file", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	reader, err := l.OpenReader(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+func (l *webdavDisk) Write(ctx context.Context, path string, data []byte) error {
+	slog.Debug("writing to file", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	res, err := l.request(ctx, http.MethodPut, path, bytes.NewReader(data), nil)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := webdavStatus(res, http.StatusOK, http.StatusCreated, http.StatusNoContent); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *webdavDisk) Remove(ctx context.Context, path string) error {
+	slog.Debug("deleting path", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	res, err := l.request(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete path: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	// Deleting a path that does not exist is not an error
+	if err := webdavStatus(res, http.StatusOK, http.StatusNoContent, http.StatusNotFound); err != nil {
+		return fmt.Errorf("failed to delete path: %w", err)
+	}
+
+	return nil
+}
+
+func (l *webdavDisk) MkDir(ctx context.Context, path string) error {
+	current := ""
+	for _, segment := range strings.Split(clean(path), "/") {
+		if segment == "" {
+			continue
+		}
+
+		current += "/" + segment
+
+		slog.Debug("making directory", slog.String("dir", current), slog.String("schema", "webdav"))
+
+		res, err := l.request(ctx, "MKCOL", current+"/", nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to make directory: %w", err)
+		}
+
+		_ = res.Body.Close()
+
+		// MKCOL is not allowed on existing collections
+		if err := webdavStatus(res, http.StatusCreated, http.StatusMethodNotAllowed); err != nil {
+			return fmt.Errorf("failed to make directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *webdavDisk) ReadDir(ctx context.Context, path string) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Debug("reading directory", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	dir, err := l.client.ReadDir(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", webdavError(err))
+	}
+
+	entries := make([]Entry, len(dir))
+	for i, entry := range dir {
+		entries[i] = webdavEntry{
+			FileInfo: entry,
+		}
+	}
+
+	return entries, nil
+}
+
+func (l *webdavDisk) Open(ctx context.Context, path string, _ int) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	reader, writer := io.Pipe()
+
+	slog.Debug("opening for writing", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	go func() {
+		err := func() error {
+			res, err := l.request(ctx, http.MethodPut, path, reader, nil)
+			if err != nil {
+				return err
+			}
+
+			defer res.Body.Close()
+
+			return webdavStatus(res, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+		}()
+		if err != nil {
+			slog.Error("failed to store file", slog.Any("err", err))
+			// Unblock the writer, as nothing is reading from the pipe anymore
+			_ = reader.CloseWithError(err)
+			return
+		}
+		slog.Debug("write success", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+	}()
+
+	return contextWriter{ctx: ctx, WriteCloser: writer}, nil
+}
+
+func (l *webdavDisk) OpenReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	slog.Debug("opening for reading", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	res, err := l.request(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve path: %w", err)
+	}
+
+	if err := webdavStatus(res, http.StatusOK); err != nil {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("failed to retrieve path: %w", err)
+	}
+
+	// The body is closed once the context is cancelled
+	return res.Body, nil
+}
+
+func (l *webdavDisk) Stat(ctx context.Context, path string) (FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return FileInfo{}, err //nolint:wrapcheck
+	}
+
+	slog.Debug("getting file info", slog.String("path", clean(path)), slog.String("schema", "webdav"))
+
+	info, err := l.client.Stat(clean(path))
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get path info: %w", webdavError(err))
+	}
+
+	return FileInfo{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+	}, nil
+}
+
+func (l *webdavDisk) Rename(ctx context.Context, from string, to string) error {
+	slog.Debug("renaming path", slog.String("from", clean(from)), slog.String("to", clean(to)), slog.String("schema", "webdav"))
+
+	res, err := l.request(ctx, "MOVE", from, nil, http.Header{
+		"Destination": []string{l.url(to)},
+		"Overwrite":   []string{"T"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if err := webdavStatus(res, http.StatusCreated, http.StatusNoContent); err != nil {
+		return fmt.Errorf("failed to rename path: %w", err)
+	}
+
+	return nil
+}
+
+func (l *webdavDisk) Chmod(_ context.Context, _ string, _ os.FileMode) error {
+	// WebDAV has no concept of permissions
+	return fmt.Errorf("failed to change permissions: %w", errors.ErrUnsupported)
+}
+
+func (l *webdavDisk) url(path string) string {
+	return strings.TrimSuffix(l.root, "/") + gowebdav.PathEscape(clean(path))
+}
+
+// request sends a request using the negotiated authentication method.
+// Bodies which cannot be rewound fail if the server asks to authenticate again.
+func (l *webdavDisk) request(ctx context.Context, method string, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	authenticator, _ := l.auth.NewAuthenticator(nil)
+	defer authenticator.Close()
+
+	target := l.url(path)
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(target, "/") {
+		target += "/"
+	}
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, method, target, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		if err := authenticator.Authorize(l.http, req, clean(path)); err != nil {
+			return nil, fmt.Errorf("failed to authorize request: %w", err)
+		}
+
+		// Only rewindable bodies may be sent again, when following redirects
+		seeker, rewindable := body.(io.Seeker)
+		req.GetBody = nil
+		if rewindable {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return nil, err //nolint:wrapcheck
+				}
+				return io.NopCloser(body), nil
+			}
+		}
+
+		res, err := l.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		redo, err := authenticator.Verify(l.http, res, clean(path))
+		if err != nil {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+
+		if !redo {
+			return res, nil
+		}
+
+		_ = res.Body.Close()
+
+		if body != nil {
+			if !rewindable {
+				return nil, fmt.Errorf("failed to authenticate: %s %s cannot be sent again", method, clean(path))
+			}
+
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+		}
+	}
+}
+
+// webdavStatus returns an error if the status of the response is not one of the expected ones
+func webdavStatus(res *http.Response, expected ...int) error {
+	for _, status := range expected {
+		if res.StatusCode == status {
+			return nil
+		}
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s %s: %s: %w", res.Request.Method, res.Request.URL.Path, res.Status, os.ErrNotExist)
+	}
+
+	return fmt.Errorf("%s %s: %s", res.Request.Method, res.Request.URL.Path, res.Status)
+}
+
+// webdavError makes errors of missing paths match os.ErrNotExist
+func webdavError(err error) error {
+	if gowebdav.IsErrNotFound(err) {
+		return fmt.Errorf("%w: %w", err, os.ErrNotExist)
+	}
+
+	return err
+}
@@ -0,0 +1,102 @@
+package disk
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"golang.org/x/net/webdav"
+)
+
+// startWebDAVServer serves a temporary directory over WebDAV until the test ends.
+// Requests are authenticated with digest authentication if digest is enabled, otherwise with basic authentication.
+func startWebDAVServer(t *testing.T, user string, password string, digest bool) string {
+	t.Helper()
+
+	handler := &webdav.Handler{
+		FileSystem: webdav.Dir(t.TempDir()),
+		LockSystem: webdav.NewMemLS(),
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if digest {
+			if !checkDigest(r, user, password) {
+				w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="nonce", qop="auth", algorithm=MD5`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		} else if u, p, ok := r.BasicAuth(); !ok || u != user || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func checkDigest(r *http.Request, user string, password string) bool {
+	header, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest ")
+	if !ok {
+		return false
+	}
+
+	params := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		params[key] = strings.Trim(value, `"`)
+	}
+
+	hash := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	ha1 := hash(user + ":test:" + password)
+	ha2 := hash(r.Method + ":" + params["uri"])
+	expected := hash(fmt.Sprintf("%s:nonce:%s:%s:auth:%s", ha1, params["nc"], params["cnonce"], ha2))
+
+	return params["username"] == user && params["response"] == expected
+}
+
+func TestWebDAVDigestAuth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// Not supported
+		return
+	}
+
+	ctx := context.Background()
+	address := startWebDAVServer(t, "user", "pass", true)
+
+	d, err := FromPath("webdav://user:pass@" + address + "/")
+	testza.AssertNoError(t, err)
+
+	testza.AssertNoError(t, d.MkDir(ctx, "/mods/Mod"))
+	testza.AssertNoError(t, d.Write(ctx, "/mods/Mod/Mod.uplugin", []byte("1.0.0")))
+
+	// Streamed uploads use the negotiated authentication
+	w, err := d.Open(ctx, "/mods/Mod/Mod.pak", os.O_CREATE|os.O_RDWR)
+	testza.AssertNoError(t, err)
+	_, err = w.Write([]byte("pak"))
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, w.Close())
+
+	testza.AssertNoError(t, waitFor(func() bool {
+		data, err := d.Read(ctx, "/mods/Mod/Mod.pak")
+		return err == nil && string(data) == "pak"
+	}))
+
+	_, err = FromPath("webdav://user:wrong@" + address + "/")
+	testza.AssertNotNil(t, err)
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+)
+
+// maxHistoryEntries is the amount of applies kept in the history of each installation
+const maxHistoryEntries = 50
+
+// HistoryEntry records a successful apply of an installation
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// Profile is the profile as it was applied, nil for vanilla applies
+	Profile  *Profile           `json:"profile,omitempty"`
+	LockFile *resolver.LockFile `json:"lockfile"`
+	Changes  HookChanges        `json:"changes"`
+	ID       int                `json:"id"`
+	// RollbackOf is the ID of the entry restored by this apply
+	RollbackOf int `json:"rollback_of,omitempty"`
+}
+
+// localKey identifies the installation in files stored in the local directory.
+// Paths may contain characters which are not allowed in file names, and names may change.
+func (i *Installation) localKey() string {
+	hash := sha256.Sum256([]byte(i.Path))
+	return hex.EncodeToString(hash[:8])
+}
+
+func (i *Installation) historyFile() string {
+	return filepath.Join(viper.GetString("local-dir"), "history", i.localKey()+".json")
+}
+
+// History returns the recorded applies of the installation, oldest first
+func (i *Installation) History() ([]*HistoryEntry, error) {
+	data, err := os.ReadFile(i.historyFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var history []*HistoryEntry
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+
+	return history, nil
+}
+
+// HistoryEntry returns the entry with the ID, or the entry before the latest if the ID is 0
+func (i *Installation) HistoryEntry(id int) (*HistoryEntry, error) {
+	history, err := i.History()
+	if err != nil {
+		return nil, err
+	}
+
+	if id == 0 {
+		if len(history) < 2 {
+			return nil, errors.New("no previous apply recorded")
+		}
+		return history[len(history)-2], nil
+	}
+
+	for _, entry := range history {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("history entry %d not found", id)
+}
+
+func (i *Installation) recordHistory(global *GlobalContext, changes HookChanges, lockfile *resolver.LockFile, options InstallOptions) error {
+	if viper.GetBool("dry-run") {
+		slog.Info("dry-run: skipping history saving")
+		return nil
+	}
+
+	history, err := i.History()
+	if err != nil {
+		return err
+	}
+
+	entry := &HistoryEntry{
+		ID:         1,
+		Time:       time.Now(),
+		LockFile:   lockfile,
+		Changes:    changes,
+		RollbackOf: options.rollbackOf,
+	}
+
+	switch {
+	case options.rollbackOf != 0:
+		entry.Profile = options.profile
+	case !i.Vanilla:
+		entry.Profile = global.Profiles.Profiles[i.Profile]
+	}
+
+	if len(history) > 0 {
+		entry.ID = history[len(history)-1].ID + 1
+	}
+
+	history = append(history, entry)
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+
+	historyJSON, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(i.historyFile()), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	if err := os.WriteFile(i.historyFile(), historyJSON, 0o755); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+func (i *Installation) deleteHistory() error {
+	if viper.GetBool("dry-run") {
+		return nil
+	}
+
+	if err := os.Remove(i.historyFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete history: %w", err)
+	}
+
+	return nil
+}
+
+// Rollback installs the lockfile and deploys the configs of the history entry with the ID,
+// or of the entry before the latest if the ID is 0.
+// Mods are reinstalled from the download cache when available.
+func (i *Installation) Rollback(ctx context.Context, global *GlobalContext, id int, options InstallOptions, updates chan<- InstallUpdate) error {
+	entry, err := i.HistoryEntry(id)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("rolling back installation", slog.String("path", i.Path), slog.Int("id", entry.ID))
+
+	options.lockfile = entry.LockFile
+	if options.lockfile == nil {
+		options.lockfile = resolver.NewLockfile()
+	}
+	options.rollbackOf = entry.ID
+	options.profile = entry.Profile
+
+	return i.Install(ctx, global, options, updates)
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestHistoryRollback(t *testing.T) {
+	ctx, installation, d := newMemoryInstallation(t, "HistoryTest")
+
+	_, err := installation.HistoryEntry(0)
+	testza.AssertNotNil(t, err)
+
+	profile := ctx.Profiles.Profiles["HistoryTest"]
+	testza.AssertNoError(t, profile.SetConfig("FactoryGame/Configs/AreaActions.cfg", "first", false))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	profile.RemoveMod("AreaActions")
+	testza.AssertNoError(t, profile.SetConfig("FactoryGame/Configs/AreaActions.cfg", "second", false))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	history, err := installation.History()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, history, 2)
+	testza.AssertEqual(t, 1, history[0].ID)
+	testza.AssertEqual(t, map[string]string{"AreaActions": "1.0.0", "SML": "3.6.0"}, history[0].Changes.Added)
+	testza.AssertTrue(t, history[0].Profile.HasMod("AreaActions"))
+	testza.AssertEqual(t, map[string]string{"AreaActions": "1.0.0", "SML": "3.6.0"}, history[1].Changes.Removed)
+
+	// Rolls back to the apply before the latest
+	err = installation.Rollback(context.Background(), ctx, 0, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	lockfile, err := installation.LockFile(context.Background(), ctx)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.0.0", lockfile.Mods["AreaActions"].Version)
+
+	// The configs of the entry are deployed rather than those of the current profile
+	config, err := d.Read(context.Background(), "/server/FactoryGame/Configs/AreaActions.cfg")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "first", string(config))
+
+	history, err = installation.History()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, history, 3)
+	testza.AssertEqual(t, 1, history[2].RollbackOf)
+	testza.AssertTrue(t, history[2].Profile.HasMod("AreaActions"))
+
+	err = installation.Rollback(context.Background(), ctx, 2, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	config, err = d.Read(context.Background(), "/server/FactoryGame/Configs/AreaActions.cfg")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "second", string(config))
+
+	err = installation.Rollback(context.Background(), ctx, 10, InstallOptions{}, installWatcher())
+	testza.AssertNotNil(t, err)
+
+	// Deleting the installation deletes its history
+	testza.AssertNoError(t, ctx.Installations.DeleteInstallation(installation.Path))
+
+	history, err = installation.History()
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, history, 0)
+}
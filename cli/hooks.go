@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+type HookEvent string
+
+const (
+	// HookEventPreResolve runs before the profile of the installation is resolved
+	HookEventPreResolve HookEvent = "pre-resolve"
+	// HookEventPreApply runs after resolving, before any file of the installation is changed
+	HookEventPreApply HookEvent = "pre-apply"
+	// HookEventPostApply runs after the installation completed successfully
+	HookEventPostApply HookEvent = "post-apply"
+	// HookEventFailure runs after the installation failed, including when a hook aborted it
+	HookEventFailure HookEvent = "failure"
+)
+
+var HookEvents = []HookEvent{HookEventPreResolve, HookEventPreApply, HookEventPostApply, HookEventFailure}
+
+type HookType string
+
+const (
+	// HookTypeLocal runs the command on this machine
+	HookTypeLocal HookType = "local"
+	// HookTypeRemote runs the command on the server of the installation, over SSH for SFTP installations
+	HookTypeRemote HookType = "remote"
+	// HookTypeHTTP sends the changes as JSON in a POST request to the URL
+	HookTypeHTTP HookType = "http"
+)
+
+// Hook runs a command or calls a URL when an installation is applied.
+// A failing command or request aborts the apply, unless IgnoreFailure is set.
+type Hook struct {
+	Event         HookEvent `json:"event"`
+	Type          HookType  `json:"type"`
+	Command       string    `json:"command,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	IgnoreFailure bool      `json:"ignore_failure,omitempty"`
+}
+
+// HookChanges describes the apply a hook runs for.
+// Mods are mapped to their new version, and removed mods to their old version.
+type HookChanges struct {
+	Mods         map[string]string `json:"mods"`
+	Added        map[string]string `json:"added"`
+	Updated      map[string]string `json:"updated"`
+	Removed      map[string]string `json:"removed"`
+	Event        HookEvent         `json:"event"`
+	Installation string            `json:"installation"`
+	Name         string            `json:"name,omitempty"`
+	Profile      string            `json:"profile"`
+	Target       string            `json:"target"`
+	Error        string            `json:"error,omitempty"`
+	Vanilla      bool              `json:"vanilla"`
+}
+
+func (h Hook) Validate() error {
+	if !slices.Contains(HookEvents, h.Event) {
+		return fmt.Errorf("unknown hook event: %s", h.Event)
+	}
+
+	switch h.Type {
+	case HookTypeLocal, HookTypeRemote:
+		if h.Command == "" {
+			return fmt.Errorf("%s hooks require a command", h.Type)
+		}
+	case HookTypeHTTP:
+		if !strings.HasPrefix(h.URL, "http://") && !strings.HasPrefix(h.URL, "https://") {
+			return fmt.Errorf("http hooks require an http or https url, got: %s", h.URL)
+		}
+	default:
+		return fmt.Errorf("unknown hook type: %s", h.Type)
+	}
+
+	return nil
+}
+
+func (h Hook) String() string {
+	s := string(h.Event) + " " + string(h.Type) + ": "
+	if h.Type == HookTypeHTTP {
+		s += h.URL
+	} else {
+		s += h.Command
+	}
+
+	if h.IgnoreFailure {
+		s += " (failures ignored)"
+	}
+
+	return s
+}
+
+// AddHook adds a hook to the installation, which runs after the existing hooks of the same event
+func (i *Installation) AddHook(hook Hook) error {
+	if err := hook.Validate(); err != nil {
+		return err
+	}
+
+	if hook.Type == HookTypeRemote {
+		d, err := i.GetDisk()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := d.(disk.Runner); !ok {
+			return errors.New("remote hooks are only supported by installations which can run commands, like SFTP")
+		}
+	}
+
+	i.Hooks = append(i.Hooks, hook)
+
+	return nil
+}
+
+// RemoveHook removes the hook at the index of Hooks
+func (i *Installation) RemoveHook(index int) error {
+	if index < 0 || index >= len(i.Hooks) {
+		return fmt.Errorf("hook %d does not exist", index)
+	}
+
+	i.Hooks = append(i.Hooks[:index], i.Hooks[index+1:]...)
+	if len(i.Hooks) == 0 {
+		i.Hooks = nil
+	}
+
+	return nil
+}
+
+func (i *Installation) hookChanges(platform *Platform) HookChanges {
+	return HookChanges{
+		Installation: i.redactedPath(),
+		Name:         i.Name,
+		Profile:      i.Profile,
+		Target:       platform.TargetName,
+		Vanilla:      i.Vanilla,
+	}
+}
+
+// redactedPath returns the path of the installation without the password of remote installations,
+// as the changes are passed to hooks and recorded in the history
+func (i *Installation) redactedPath() string {
+	parsed, err := url.Parse(i.Path)
+	if err != nil || !isRemoteScheme(parsed.Scheme) {
+		return i.Path
+	}
+
+	return parsed.Redacted()
+}
+
+// setLockFiles fills the mods of the changes from the lockfile before and after resolving
+func (c *HookChanges) setLockFiles(current *resolver.LockFile, lockfile *resolver.LockFile) {
+	c.Mods = make(map[string]string)
+	c.Added = make(map[string]string)
+	c.Updated = make(map[string]string)
+	c.Removed = make(map[string]string)
+
+	for modReference, mod := range lockfile.Mods {
+		c.Mods[modReference] = mod.Version
+
+		var old resolver.LockedMod
+		var ok bool
+		if current != nil {
+			old, ok = current.Mods[modReference]
+		}
+
+		if !ok {
+			c.Added[modReference] = mod.Version
+		} else if old.Version != mod.Version {
+			c.Updated[modReference] = mod.Version
+		}
+	}
+
+	if current != nil {
+		for modReference, mod := range current.Mods {
+			if _, ok := lockfile.Mods[modReference]; !ok {
+				c.Removed[modReference] = mod.Version
+			}
+		}
+	}
+}
+
+// Env returns the environment variables describing the changes to command hooks.
+// Mod lists are sorted and space separated, in the form ModReference@version.
+func (c HookChanges) Env() map[string]string {
+	formatMods := func(mods map[string]string) string {
+		list := make([]string, 0, len(mods))
+		for modReference, version := range mods {
+			list = append(list, modReference+"@"+version)
+		}
+		slices.Sort(list)
+		return strings.Join(list, " ")
+	}
+
+	return map[string]string{
+		"FICSIT_HOOK_EVENT":        string(c.Event),
+		"FICSIT_INSTALLATION":      c.Installation,
+		"FICSIT_INSTALLATION_NAME": c.Name,
+		"FICSIT_PROFILE":           c.Profile,
+		"FICSIT_TARGET":            c.Target,
+		"FICSIT_VANILLA":           strconv.FormatBool(c.Vanilla),
+		"FICSIT_MODS":              formatMods(c.Mods),
+		"FICSIT_MODS_ADDED":        formatMods(c.Added),
+		"FICSIT_MODS_UPDATED":      formatMods(c.Updated),
+		"FICSIT_MODS_REMOVED":      formatMods(c.Removed),
+		"FICSIT_ERROR":             c.Error,
+	}
+}
+
+// runHooks runs the hooks of the event in order, stopping at the first failing hook which does not ignore failures
+func (i *Installation) runHooks(ctx context.Context, event HookEvent, changes HookChanges) error {
+	changes.Event = event
+
+	for _, hook := range i.Hooks {
+		if hook.Event != event {
+			continue
+		}
+
+		slog.Info("running hook", slog.String("path", i.Path), slog.String("hook", hook.String()))
+
+		if err := i.runHook(ctx, hook, changes); err != nil {
+			if !hook.IgnoreFailure {
+				return fmt.Errorf("%s hook failed: %w", event, err)
+			}
+
+			slog.Warn("ignoring failed hook", slog.String("hook", hook.String()), slog.Any("err", err))
+		}
+	}
+
+	return nil
+}
+
+func (i *Installation) runHook(ctx context.Context, hook Hook, changes HookChanges) error {
+	switch hook.Type {
+	case HookTypeLocal:
+		output, err := runLocalCommand(ctx, hook.Command, changes.Env())
+		logHookOutput(hook, output)
+
+		if err != nil {
+			return err
+		}
+	case HookTypeRemote:
+		d, err := i.GetDisk()
+		if err != nil {
+			return err
+		}
+
+		runner, ok := d.(disk.Runner)
+		if !ok {
+			return errors.New("the installation cannot run remote commands")
+		}
+
+		output, err := runner.Run(ctx, hook.Command, changes.Env())
+		logHookOutput(hook, output)
+
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+	case HookTypeHTTP:
+		body, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("failed to serialize changes: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := utils.HTTPClient().Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+
+		defer res.Body.Close()
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return fmt.Errorf("request returned %s", res.Status)
+		}
+	default:
+		return fmt.Errorf("unknown hook type: %s", hook.Type)
+	}
+
+	return nil
+}
+
+// runLocalCommand runs the command in the shell of this machine, with the env added to the environment
+func runLocalCommand(ctx context.Context, command string, env map[string]string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return output, &disk.ExitError{Code: exitErr.ExitCode()}
+		}
+		return output, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return output, nil
+}
+
+func logHookOutput(hook Hook, output []byte) {
+	if len(bytes.TrimSpace(output)) > 0 {
+		slog.Info("hook output", slog.String("hook", hook.String()), slog.String("output", string(bytes.TrimSpace(output))))
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
 	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"github.com/satisfactorymodding/ficsit-cli/cli/localregistry"
 	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
 
@@ -40,10 +42,17 @@ type Installations struct {
 }
 
 type Installation struct {
-	DiskInstance disk.Disk `json:"-"`
-	Path         string    `json:"path"`
-	Profile      string    `json:"profile"`
-	Vanilla      bool      `json:"vanilla"`
+	DiskInstance disk.Disk         `json:"-"`
+	Path         string            `json:"path"`
+	Profile      string            `json:"profile"`
+	Name         string            `json:"name,omitempty"`
+	Notes        string            `json:"notes,omitempty"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Hooks        []Hook            `json:"hooks,omitempty"`
+	RunningProbe string            `json:"running_probe,omitempty"`
+	AutoBackups  int               `json:"auto_backups,omitempty"`
+	Vanilla      bool              `json:"vanilla"`
 }
 
 func InitInstallations() (*Installations, error) {
@@ -116,14 +125,14 @@ func (i *Installations) Save() error {
 	return nil
 }
 
-func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string, profile string) (*Installation, error) {
+func (i *Installations) AddInstallation(ctx context.Context, global *GlobalContext, installPath string, profile string) (*Installation, error) {
 	parsed, err := url.Parse(installPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse path: %w", err)
 	}
 
 	absolutePath := installPath
-	if parsed.Scheme != "ftp" && parsed.Scheme != "sftp" {
+	if !isRemoteScheme(parsed.Scheme) {
 		absolutePath, err = filepath.Abs(installPath)
 
 		if err != nil {
@@ -137,7 +146,7 @@ func (i *Installations) AddInstallation(ctx *GlobalContext, installPath string,
 		Vanilla: false,
 	}
 
-	if err := installation.Validate(ctx); err != nil {
+	if err := installation.Validate(ctx, global); err != nil {
 		return nil, fmt.Errorf("failed to validate installation: %w", err)
 	}
 
@@ -168,29 +177,147 @@ func (i *Installations) GetInstallation(installPath string) *Installation {
 	return nil
 }
 
-func (i *Installations) DeleteInstallation(installPath string) error {
-	found := -1
+// FindInstallation returns the installation with the name or path
+func (i *Installations) FindInstallation(nameOrPath string) *Installation {
+	for _, install := range i.Installations {
+		if install.Name != "" && install.Name == nameOrPath {
+			return install
+		}
+	}
+
+	return i.GetInstallation(nameOrPath)
+}
+
+// SelectInstallations returns the installations with any of the names or paths, or tagged with any of the tags.
+// Every name, path and tag must match at least one installation.
+func (i *Installations) SelectInstallations(namesOrPaths []string, tags []string) ([]*Installation, error) {
+	selected := make(map[*Installation]bool)
+
+	for _, nameOrPath := range namesOrPaths {
+		install := i.FindInstallation(nameOrPath)
+		if install == nil {
+			return nil, fmt.Errorf("installation not found: %s", nameOrPath)
+		}
+		selected[install] = true
+	}
+
+	for _, tag := range tags {
+		found := false
+		for _, install := range i.Installations {
+			if install.HasTag(tag) {
+				selected[install] = true
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no installation tagged: %s", tag)
+		}
+	}
+
+	// Keep the order of the installations
+	var installations []*Installation
+	for _, install := range i.Installations {
+		if selected[install] {
+			installations = append(installations, install)
+		}
+	}
+
+	return installations, nil
+}
+
+// SetInstallationName changes the name of an installation, an empty name removes it.
+// Names must be unique, and cannot be the path of another installation.
+func (i *Installations) SetInstallationName(installation *Installation, name string) error {
+	name = strings.TrimSpace(name)
+
+	if name != "" {
+		for _, install := range i.Installations {
+			if install != installation && (install.Name == name || install.Path == name) {
+				return fmt.Errorf("installation name already in use: %s", name)
+			}
+		}
+	}
+
+	installation.Name = name
+
+	return nil
+}
+
+func (i *Installations) DeleteInstallation(nameOrPath string) error {
+	installation := i.FindInstallation(nameOrPath)
+	if installation == nil {
+		return errors.New("installation not found")
+	}
+
 	for j, install := range i.Installations {
-		if install.Path == installPath {
-			found = j
+		if install == installation {
+			i.Installations = append(i.Installations[:j], i.Installations[j+1:]...)
 			break
 		}
 	}
 
-	if found == -1 {
-		return errors.New("installation not found")
+	return installation.deleteHistory()
+}
+
+// DisplayName returns the name of the installation, or its path if it has no name
+func (i *Installation) DisplayName() string {
+	if i.Name != "" {
+		return i.Name
 	}
 
-	i.Installations = append(i.Installations[:found], i.Installations[found+1:]...)
+	return i.Path
+}
 
-	return nil
+// HasTag checks if the installation is tagged with the tag, ignoring case
+func (i *Installation) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddTags tags the installation, skipping tags it already has
+func (i *Installation) AddTags(tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !i.HasTag(tag) {
+			i.Tags = append(i.Tags, tag)
+		}
+	}
+}
+
+// RemoveTags removes the tags from the installation, ignoring case
+func (i *Installation) RemoveTags(tags ...string) {
+	kept := i.Tags[:0]
+	for _, t := range i.Tags {
+		removed := false
+		for _, tag := range tags {
+			if strings.EqualFold(t, strings.TrimSpace(tag)) {
+				removed = true
+				break
+			}
+		}
+
+		if !removed {
+			kept = append(kept, t)
+		}
+	}
+
+	i.Tags = kept
+	if len(i.Tags) == 0 {
+		i.Tags = nil
+	}
 }
 
 var rootExecutables = []string{"FactoryGame.exe", "FactoryServer.sh", "FactoryServer.exe", "FactoryGameSteam.exe", "FactoryGameEGS.exe"}
 
-func (i *Installation) Validate(ctx *GlobalContext) error {
+func (i *Installation) Validate(ctx context.Context, global *GlobalContext) error {
 	found := false
-	for _, p := range ctx.Profiles.Profiles {
+	for _, p := range global.Profiles.Profiles {
 		if p.Name == i.Profile {
 			found = true
 			break
@@ -213,7 +340,7 @@ func (i *Installation) Validate(ctx *GlobalContext) error {
 	for _, executable := range rootExecutables {
 		e := executable
 		checkWait.Go(func() error {
-			exists, err := d.Exists(filepath.Join(i.BasePath(), e))
+			exists, err := d.Exists(ctx, filepath.Join(i.BasePath(), e))
 			if !exists {
 				if err != nil {
 					return fmt.Errorf("failed reading %s: %w", e, err)
@@ -242,8 +369,8 @@ var (
 	matchAllCap     = regexp.MustCompile(`([a-z\d])([A-Z])`)
 )
 
-func (i *Installation) lockFilePath(ctx *GlobalContext, platform *Platform) string {
-	lockFileName := ctx.Profiles.Profiles[i.Profile].Name
+func (i *Installation) lockFilePath(global *GlobalContext, platform *Platform) string {
+	lockFileName := global.Profiles.Profiles[i.Profile].Name
 	lockFileName = matchFirstCap.ReplaceAllString(lockFileName, "${1}_${2}")
 	lockFileName = matchAllCap.ReplaceAllString(lockFileName, "${1}_${2}")
 	lockFileName = lockFileCleaner.ReplaceAllLiteralString(lockFileName, "-")
@@ -252,15 +379,15 @@ func (i *Installation) lockFilePath(ctx *GlobalContext, platform *Platform) stri
 	return filepath.Join(i.BasePath(), platform.LockfilePath, lockFileName)
 }
 
-func (i *Installation) lockfile(ctx *GlobalContext, platform *Platform) (*resolver.LockFile, error) {
-	lockfilePath := i.lockFilePath(ctx, platform)
+func (i *Installation) lockfile(ctx context.Context, global *GlobalContext, platform *Platform) (*resolver.LockFile, error) {
+	lockfilePath := i.lockFilePath(global, platform)
 
 	d, err := i.GetDisk()
 	if err != nil {
 		return nil, err
 	}
 
-	exists, err := d.Exists(lockfilePath)
+	exists, err := d.Exists(ctx, lockfilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +397,7 @@ func (i *Installation) lockfile(ctx *GlobalContext, platform *Platform) (*resolv
 	}
 
 	var lockFile *resolver.LockFile
-	lockFileJSON, err := d.Read(lockfilePath)
+	lockFileJSON, err := d.Read(ctx, lockfilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed reading lockfile: %w", err)
 	}
@@ -282,8 +409,8 @@ func (i *Installation) lockfile(ctx *GlobalContext, platform *Platform) (*resolv
 	return lockFile, nil
 }
 
-func (i *Installation) writeLockFile(ctx *GlobalContext, platform *Platform, lockfile *resolver.LockFile) error {
-	lockfilePath := i.lockFilePath(ctx, platform)
+func (i *Installation) writeLockFile(ctx context.Context, global *GlobalContext, platform *Platform, lockfile *resolver.LockFile) error {
+	lockfilePath := i.lockFilePath(global, platform)
 
 	d, err := i.GetDisk()
 	if err != nil {
@@ -291,12 +418,12 @@ func (i *Installation) writeLockFile(ctx *GlobalContext, platform *Platform, loc
 	}
 
 	lockfileDir := filepath.Dir(lockfilePath)
-	if exists, err := d.Exists(lockfileDir); !exists {
+	if exists, err := d.Exists(ctx, lockfileDir); !exists {
 		if err != nil {
 			return err
 		}
 
-		if err := d.MkDir(lockfileDir); err != nil {
+		if err := d.MkDir(ctx, lockfileDir); err != nil {
 			return fmt.Errorf("failed creating lockfile directory: %w", err)
 		}
 	}
@@ -306,14 +433,14 @@ func (i *Installation) writeLockFile(ctx *GlobalContext, platform *Platform, loc
 		return fmt.Errorf("failed to serialize lockfile json: %w", err)
 	}
 
-	if err := d.Write(lockfilePath, marshaledLockfile); err != nil {
+	if err := d.Write(ctx, lockfilePath, marshaledLockfile); err != nil {
 		return fmt.Errorf("failed writing lockfile: %w", err)
 	}
 
 	return nil
 }
 
-func (i *Installation) Wipe() error {
+func (i *Installation) Wipe(ctx context.Context) error {
 	slog.Info("wiping installation", slog.String("path", i.Path))
 
 	d, err := i.GetDisk()
@@ -322,60 +449,53 @@ func (i *Installation) Wipe() error {
 	}
 
 	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
-	if err := d.Remove(modsDirectory); err != nil {
+	if err := d.Remove(ctx, modsDirectory); err != nil {
 		return fmt.Errorf("failed removing Mods directory: %w", err)
 	}
 
 	return nil
 }
 
-func (i *Installation) resolveProfile(ctx *GlobalContext, platform *Platform) (*resolver.LockFile, error) {
-	lockFile, err := i.lockfile(ctx, platform)
-	if err != nil {
-		return nil, err
-	}
-
-	depResolver := resolver.NewDependencyResolver(ctx.Provider)
-
-	gameVersion, err := i.getGameVersion(platform)
+func (i *Installation) resolveProfile(ctx context.Context, global *GlobalContext, platform *Platform, lockFile *resolver.LockFile) (*resolver.LockFile, error) {
+	gameVersion, err := i.getGameVersion(ctx, platform)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect game version: %w", err)
 	}
 
-	lockfile, err := ctx.Profiles.Profiles[i.Profile].Resolve(depResolver, lockFile, gameVersion)
+	profile := global.Profiles.Profiles[i.Profile]
+
+	lockfile, err := profile.Resolve(global.Provider, lockFile, gameVersion)
 	if err != nil {
-		return nil, fmt.Errorf("could not resolve mods: %w", err)
+		return nil, fmt.Errorf("could not resolve mods: %w", explainResolveError(ctx, global, profile, err))
 	}
 
-	if err := i.writeLockFile(ctx, platform, lockfile); err != nil {
-		return nil, fmt.Errorf("failed to write lockfile: %w", err)
-	}
+	logTargetExclusions(profile, lockfile)
 
 	return lockfile, nil
 }
 
-func (i *Installation) GetGameVersion(ctx *GlobalContext) (int, error) {
-	platform, err := i.GetPlatform(ctx)
+func (i *Installation) GetGameVersion(ctx context.Context, global *GlobalContext) (int, error) {
+	platform, err := i.GetPlatform(ctx, global)
 	if err != nil {
 		return 0, err
 	}
-	return i.getGameVersion(platform)
+	return i.getGameVersion(ctx, platform)
 }
 
-func (i *Installation) LockFile(ctx *GlobalContext) (*resolver.LockFile, error) {
-	platform, err := i.GetPlatform(ctx)
+func (i *Installation) LockFile(ctx context.Context, global *GlobalContext) (*resolver.LockFile, error) {
+	platform, err := i.GetPlatform(ctx, global)
 	if err != nil {
 		return nil, err
 	}
-	return i.lockfile(ctx, platform)
+	return i.lockfile(ctx, global, platform)
 }
 
-func (i *Installation) WriteLockFile(ctx *GlobalContext, lockfile *resolver.LockFile) error {
-	platform, err := i.GetPlatform(ctx)
+func (i *Installation) WriteLockFile(ctx context.Context, global *GlobalContext, lockfile *resolver.LockFile) error {
+	platform, err := i.GetPlatform(ctx, global)
 	if err != nil {
 		return err
 	}
-	return i.writeLockFile(ctx, platform, lockfile)
+	return i.writeLockFile(ctx, global, platform, lockfile)
 }
 
 type InstallUpdateType string
@@ -385,11 +505,13 @@ var (
 	InstallUpdateTypeModDownload InstallUpdateType = "download"
 	InstallUpdateTypeModExtract  InstallUpdateType = "extract"
 	InstallUpdateTypeModComplete InstallUpdateType = "complete"
+	InstallUpdateTypeWaiting     InstallUpdateType = "waiting"
 )
 
 type InstallUpdate struct {
 	Type     InstallUpdateType
 	Item     InstallUpdateItem
+	Message  string
 	Progress utils.GenericProgress
 }
 
@@ -400,40 +522,104 @@ type InstallUpdateItem struct {
 
 var modRoots = []string{"", "GameFeatures"}
 
-func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate) error {
-	platform, err := i.GetPlatform(ctx)
+func (i *Installation) Install(ctx context.Context, global *GlobalContext, options InstallOptions, updates chan<- InstallUpdate) error {
+	platform, err := i.GetPlatform(ctx, global)
 	if err != nil {
 		return fmt.Errorf("failed to detect platform: %w", err)
 	}
 
+	changes := i.hookChanges(platform)
+
+	lockfile, err := i.install(ctx, global, platform, &changes, options, updates)
+	if err != nil {
+		changes.Error = err.Error()
+
+		// Failure hooks also run when the installation was cancelled, for example to restart a stopped server
+		if hookErr := i.runHooks(context.WithoutCancel(ctx), HookEventFailure, changes); hookErr != nil {
+			slog.Error("failed to run hooks", slog.String("path", i.Path), slog.Any("err", hookErr))
+		}
+
+		return err
+	}
+
+	if err := i.recordHistory(global, changes, lockfile, options); err != nil {
+		slog.Error("failed to record history", slog.String("path", i.Path), slog.Any("err", err))
+	}
+
+	if err := i.runHooks(ctx, HookEventPostApply, changes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *Installation) install(ctx context.Context, global *GlobalContext, platform *Platform, changes *HookChanges, options InstallOptions, updates chan<- InstallUpdate) (*resolver.LockFile, error) {
+	if err := i.runHooks(ctx, HookEventPreResolve, *changes); err != nil {
+		return nil, err
+	}
+
+	currentLockfile, err := i.lockfile(ctx, global, platform)
+	if err != nil {
+		return nil, err
+	}
+
 	lockfile := resolver.NewLockfile()
 
-	if !i.Vanilla {
+	if options.lockfile != nil {
+		lockfile = options.lockfile
+	} else if !i.Vanilla {
 		var err error
-		lockfile, err = i.resolveProfile(ctx, platform)
+		lockfile, err = i.resolveProfile(ctx, global, platform, currentLockfile)
 		if err != nil {
-			return fmt.Errorf("failed to resolve lockfile: %w", err)
+			return nil, fmt.Errorf("failed to resolve lockfile: %w", err)
+		}
+	}
+
+	changes.setLockFiles(currentLockfile, lockfile)
+
+	if err := i.runHooks(ctx, HookEventPreApply, *changes); err != nil {
+		return nil, err
+	}
+
+	// Checked after the pre-apply hooks, as they may stop the server
+	if err := i.checkRunning(ctx, global, platform, options, updates); err != nil {
+		return nil, err
+	}
+
+	configs, err := i.prepareConfigs(ctx, global, platform, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.autoBackup(ctx, changes); err != nil {
+		return nil, err
+	}
+
+	if !i.Vanilla || options.lockfile != nil {
+		if err := i.writeLockFile(ctx, global, platform, lockfile); err != nil {
+			return nil, fmt.Errorf("failed to write lockfile: %w", err)
 		}
 	}
 
 	d, err := i.GetDisk()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	modsDirectory := filepath.Join(i.BasePath(), "FactoryGame", "Mods")
-	if err := d.MkDir(modsDirectory); err != nil {
-		return fmt.Errorf("failed creating Mods directory: %w", err)
+	if err := d.MkDir(ctx, modsDirectory); err != nil {
+		return nil, fmt.Errorf("failed creating Mods directory: %w", err)
 	}
 
-	oldModLocations, err := getExistingMods(d, modsDirectory)
+	oldModLocations, err := getExistingMods(ctx, d, modsDirectory)
 	if err != nil {
-		return fmt.Errorf("failed to get existing mods: %w", err)
+		return nil, fmt.Errorf("failed to get existing mods: %w", err)
 	}
 
 	slog.Info("starting installation", slog.Int("concurrency", viper.GetInt("concurrent-downloads")), slog.String("path", i.Path))
 
-	errg := errgroup.Group{}
+	// The first failing mod cancels the installation of the others
+	errg, installCtx := errgroup.WithContext(ctx)
 	channelUsers := sync.WaitGroup{}
 	downloadSemaphore := make(chan int, viper.GetInt("concurrent-downloads"))
 	defer close(downloadSemaphore)
@@ -479,7 +665,7 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 
 			// Only install if a link is provided, otherwise assume mod is already installed
 			if target.Link != "" {
-				location, err := downloadAndExtractMod(modReference, version.Version, target.Link, target.Hash, platform.TargetName, modsDirectory, updates, downloadSemaphore, d)
+				location, err := downloadAndExtractMod(installCtx, modReference, version.Version, target.Link, target.Hash, platform.TargetName, modsDirectory, updates, downloadSemaphore, d)
 				if err != nil {
 					return fmt.Errorf("failed to install %s@%s: %w", modReference, version.Version, err)
 				}
@@ -494,7 +680,7 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 	}
 
 	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("failed to install mods: %w", err)
+		return nil, fmt.Errorf("failed to install mods: %w", err)
 	}
 
 	newModLocations.Range(func(mod, location string) bool {
@@ -509,14 +695,13 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 		return true
 	})
 
-	var deleteWait errgroup.Group
+	deleteWait, deleteCtx := errgroup.WithContext(ctx)
 	for modName, modLocations := range oldModLocations {
 		for modLocation := range modLocations {
 			modDir := filepath.Join(modsDirectory, modLocation)
+			slog.Info("deleting mod", slog.String("mod_reference", modName))
 			deleteWait.Go(func() error {
-				slog.Info("deleting mod", slog.String("mod_reference", modName))
-
-				if err := d.Remove(modDir); err != nil {
+				if err := d.Remove(deleteCtx, modDir); err != nil {
 					return fmt.Errorf("failed to delete mod directory: %w", err)
 				}
 
@@ -526,7 +711,11 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 	}
 
 	if err := deleteWait.Wait(); err != nil {
-		return fmt.Errorf("failed to remove old mods: %w", err)
+		return nil, fmt.Errorf("failed to remove old mods: %w", err)
+	}
+
+	if err := i.deployConfigs(ctx, platform, configs); err != nil {
+		return nil, err
 	}
 
 	if updates != nil {
@@ -548,14 +737,14 @@ func (i *Installation) Install(ctx *GlobalContext, updates chan<- InstallUpdate)
 
 	slog.Info("installation completed", slog.String("path", i.Path))
 
-	return nil
+	return lockfile, nil
 }
 
-func getExistingMods(d disk.Disk, modsDirectory string) (map[string]map[string]bool, error) {
+func getExistingMods(ctx context.Context, d disk.Disk, modsDirectory string) (map[string]map[string]bool, error) {
 	existingModDirectories := map[string]map[string]bool{}
 
 	for _, modRoot := range modRoots {
-		exists, err := d.Exists(filepath.Join(modsDirectory, modRoot))
+		exists, err := d.Exists(ctx, filepath.Join(modsDirectory, modRoot))
 		if err != nil {
 			return nil, fmt.Errorf("failed to check if %s exists: %w", modRoot, err)
 		}
@@ -563,7 +752,7 @@ func getExistingMods(d disk.Disk, modsDirectory string) (map[string]map[string]b
 			continue
 		}
 
-		dir, err := d.ReadDir(filepath.Join(modsDirectory, modRoot))
+		dir, err := d.ReadDir(ctx, filepath.Join(modsDirectory, modRoot))
 		if err != nil {
 			return nil, fmt.Errorf("failed to read %s directory: %w", modRoot, err)
 		}
@@ -572,7 +761,7 @@ func getExistingMods(d disk.Disk, modsDirectory string) (map[string]map[string]b
 			if entry.IsDir() {
 				location := filepath.Join(modRoot, entry.Name())
 				markerPath := filepath.Join(modsDirectory, location, ".smm")
-				ok, err := d.Exists(markerPath)
+				ok, err := d.Exists(ctx, markerPath)
 				if err != nil {
 					return nil, fmt.Errorf("failed to check if %s exists: %w", markerPath, err)
 				}
@@ -589,25 +778,23 @@ func getExistingMods(d disk.Disk, modsDirectory string) (map[string]map[string]b
 	return existingModDirectories, nil
 }
 
-func (i *Installation) UpdateMods(ctx *GlobalContext, mods []string) error {
-	platform, err := i.GetPlatform(ctx)
+func (i *Installation) UpdateMods(ctx context.Context, global *GlobalContext, mods []string) error {
+	platform, err := i.GetPlatform(ctx, global)
 	if err != nil {
 		return err
 	}
 
-	lockFile, err := i.lockfile(ctx, platform)
+	lockFile, err := i.lockfile(ctx, global, platform)
 	if err != nil {
 		return fmt.Errorf("failed to read lock file: %w", err)
 	}
 
-	resolver := resolver.NewDependencyResolver(ctx.Provider)
-
-	gameVersion, err := i.getGameVersion(platform)
+	gameVersion, err := i.getGameVersion(ctx, platform)
 	if err != nil {
 		return fmt.Errorf("failed to detect game version: %w", err)
 	}
 
-	profile := ctx.Profiles.GetProfile(i.Profile)
+	profile := global.Profiles.GetProfile(i.Profile)
 	if profile == nil {
 		return errors.New("could not find profile " + i.Profile)
 	}
@@ -616,19 +803,43 @@ func (i *Installation) UpdateMods(ctx *GlobalContext, mods []string) error {
 		lockFile = lockFile.Remove(modReference)
 	}
 
-	newLockFile, err := profile.Resolve(resolver, lockFile, gameVersion)
+	newLockFile, err := profile.Resolve(global.Provider, lockFile, gameVersion)
 	if err != nil {
-		return fmt.Errorf("failed to resolve dependencies: %w", err)
+		return fmt.Errorf("failed to resolve dependencies: %w", explainResolveError(ctx, global, profile, err))
 	}
 
-	if err := i.writeLockFile(ctx, platform, newLockFile); err != nil {
+	logTargetExclusions(profile, newLockFile)
+
+	if err := i.writeLockFile(ctx, global, platform, newLockFile); err != nil {
 		return fmt.Errorf("failed to write lock file: %w", err)
 	}
 
 	return nil
 }
 
-func downloadAndExtractMod(modReference string, version string, link string, hash string, target string, modsDirectory string, updates chan<- InstallUpdate, downloadSemaphore chan int, d disk.Disk) (string, error) {
+// registryTargetSize returns the size of a mod target as known by the local registry, or 0 if it is unknown.
+// Lockfiles do not store the size of targets.
+func registryTargetSize(modReference string, version string, target string) int64 {
+	versions, err := localregistry.GetModVersions(modReference)
+	if err != nil {
+		return 0
+	}
+
+	for _, modVersion := range versions {
+		if modVersion.Version != version {
+			continue
+		}
+		for _, modTarget := range modVersion.Targets {
+			if modTarget.TargetName == target {
+				return modTarget.Size
+			}
+		}
+	}
+
+	return 0
+}
+
+func downloadAndExtractMod(ctx context.Context, modReference string, version string, link string, hash string, target string, modsDirectory string, updates chan<- InstallUpdate, downloadSemaphore chan int, d disk.Disk) (string, error) {
 	var downloadUpdates chan utils.GenericProgress
 
 	var wg sync.WaitGroup
@@ -653,7 +864,7 @@ func downloadAndExtractMod(modReference string, version string, link string, has
 	}
 
 	slog.Info("downloading mod", slog.String("mod_reference", modReference), slog.String("version", version), slog.String("link", link))
-	reader, size, err := cache.DownloadOrCache(modReference+"_"+version+"_"+target+".zip", hash, link, downloadUpdates, downloadSemaphore)
+	reader, size, err := openModArchive(ctx, modReference, version, link, hash, target, downloadUpdates, downloadSemaphore)
 	if err != nil {
 		return "", fmt.Errorf("failed to download %s from: %s: %w", modReference, link, err)
 	}
@@ -693,7 +904,7 @@ func downloadAndExtractMod(modReference string, version string, link string, has
 	}
 
 	slog.Info("extracting mod", slog.String("mod_reference", modReference), slog.String("version", version), slog.String("link", link), slog.String("location", location))
-	if err := utils.ExtractMod(zipReader, filepath.Join(modsDirectory, location), hash, extractUpdates, d); err != nil {
+	if err := utils.ExtractMod(ctx, zipReader, io.NewSectionReader(reader, 0, size), filepath.Join(modsDirectory, location), hash, extractUpdates, d); err != nil {
 		return "", fmt.Errorf("could not extract %s: %w", modReference, err)
 	}
 
@@ -750,9 +961,9 @@ func getExtractLocation(reader *zip.Reader, modReference string) (string, error)
 	return modReference, nil
 }
 
-func (i *Installation) SetProfile(ctx *GlobalContext, profile string) error {
+func (i *Installation) SetProfile(global *GlobalContext, profile string) error {
 	found := false
-	for _, p := range ctx.Profiles.Profiles {
+	for _, p := range global.Profiles.Profiles {
 		if p.Name == profile {
 			found = true
 			break
@@ -780,7 +991,7 @@ type gameVersionFile struct {
 	IsPromotedBuild      int    `json:"IsPromotedBuild"`
 }
 
-func (i *Installation) getGameVersion(platform *Platform) (int, error) {
+func (i *Installation) getGameVersion(ctx context.Context, platform *Platform) (int, error) {
 	d, err := i.GetDisk()
 	if err != nil {
 		return 0, err
@@ -788,7 +999,7 @@ func (i *Installation) getGameVersion(platform *Platform) (int, error) {
 
 	fullPath := filepath.Join(i.BasePath(), platform.VersionPath)
 
-	file, err := d.Read(fullPath)
+	file, err := d.Read(ctx, fullPath)
 	if err != nil {
 		return 0, fmt.Errorf("failed reading version file: %w", err)
 	}
@@ -801,8 +1012,8 @@ func (i *Installation) getGameVersion(platform *Platform) (int, error) {
 	return versionData.Changelist, nil
 }
 
-func (i *Installation) GetPlatform(ctx *GlobalContext) (*Platform, error) {
-	if err := i.Validate(ctx); err != nil {
+func (i *Installation) GetPlatform(ctx context.Context, global *GlobalContext) (*Platform, error) {
+	if err := i.Validate(ctx, global); err != nil {
 		return nil, fmt.Errorf("failed to validate installation: %w", err)
 	}
 
@@ -813,7 +1024,7 @@ func (i *Installation) GetPlatform(ctx *GlobalContext) (*Platform, error) {
 
 	for _, platform := range platforms {
 		fullPath := filepath.Join(i.BasePath(), platform.VersionPath)
-		exists, err := d.Exists(fullPath)
+		exists, err := d.Exists(ctx, fullPath)
 		if !exists {
 			if err != nil {
 				return nil, fmt.Errorf("failed detecting version file: %w", err)
@@ -842,9 +1053,19 @@ func (i *Installation) BasePath() string {
 		return i.Path
 	}
 
-	if parsed.Scheme != "ftp" && parsed.Scheme != "sftp" {
+	if !isRemoteScheme(parsed.Scheme) {
 		return i.Path
 	}
 
 	return parsed.Path
 }
+
+// isRemoteScheme checks if installation paths with the scheme are on a remote disk
+func isRemoteScheme(scheme string) bool {
+	switch scheme {
+	case "ftp", "ftps", "sftp", "webdav", "webdavs", "docker", "memory":
+		return true
+	}
+
+	return false
+}
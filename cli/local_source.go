@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// localSourceScheme prefixes the links of mod versions installed from a local source.
+// Unlike archives of static repositories, local sources change between builds, so their hash is not verified.
+const localSourceScheme = "local://"
+
+// AddLocalMod adds the mod archive or directory at the given path to the profile.
+// The local source takes precedence over the repository when resolving and installing the mod.
+//
+// Sources with per-target directories provide the targets of their directories,
+// other sources only provide the given targets they were built for.
+//
+// Returns the mod reference read from the .uplugin of the source
+func (p *Profile) AddLocalMod(sourcePath string, version string, targets ...resolver.TargetName) (string, error) {
+	source, err := cache.ReadLocalSource(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read local source: %w", err)
+	}
+
+	if len(source.Targets) > 0 && len(targets) > 0 {
+		return "", fmt.Errorf("local source %s provides the targets %s of its directories, targets cannot be given", source.Path, strings.Join(source.Targets, ", "))
+	}
+
+	if len(source.Targets) == 0 && len(targets) == 0 {
+		return "", fmt.Errorf("local source %s has no per-target directories, the targets it was built for must be given", source.Path)
+	}
+
+	if err := p.AddMod(source.ModReference, version); err != nil {
+		return "", err
+	}
+
+	mod := p.Mods[source.ModReference]
+	mod.Source = source.Path
+	mod.SourceTargets = slices.Clone(targets)
+	p.Mods[source.ModReference] = mod
+
+	return source.ModReference, nil
+}
+
+// localSource returns the path of the local source of the mod, if it has one
+func (p *Profile) localSource(reference string) (string, bool) {
+	mod, ok := p.Mods[reference]
+	if !ok || mod.Source == "" {
+		return "", false
+	}
+	return mod.Source, true
+}
+
+// modVersions returns the versions of a mod available to the profile.
+// Mods with a local source only have the version of the source available.
+func (p *Profile) modVersions(ctx context.Context, provider resolver.Provider, reference string) ([]resolver.ModVersion, error) {
+	sourcePath, ok := p.localSource(reference)
+	if !ok {
+		return provider.ModVersionsWithDependencies(ctx, reference) //nolint:wrapcheck
+	}
+
+	version, err := localModVersion(sourcePath, p.Mods[reference].SourceTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	return []resolver.ModVersion{version}, nil
+}
+
+// localModVersion builds the only version available of a mod with a local source.
+// The source targets are used if the source has no per-target directories.
+func localModVersion(sourcePath string, sourceTargets []resolver.TargetName) (resolver.ModVersion, error) {
+	source, err := cache.ReadLocalSource(sourcePath)
+	if err != nil {
+		return resolver.ModVersion{}, fmt.Errorf("failed to read local source: %w", err)
+	}
+
+	dependencies := make([]resolver.Dependency, 0, len(source.UPlugin.Plugins))
+	for _, plugin := range source.UPlugin.Plugins {
+		// Engine plugins do not have a SemVersion
+		if plugin.SemVersion == "" {
+			continue
+		}
+
+		dependencies = append(dependencies, resolver.Dependency{
+			ModID:     plugin.Name,
+			Condition: plugin.SemVersion,
+			Optional:  plugin.Optional,
+		})
+	}
+
+	targetNames := sourceTargets
+	if len(source.Targets) > 0 {
+		targetNames = make([]resolver.TargetName, len(source.Targets))
+		for i, target := range source.Targets {
+			targetNames[i] = resolver.TargetName(target)
+		}
+	}
+
+	if len(targetNames) == 0 {
+		return resolver.ModVersion{}, fmt.Errorf("local source %s has no per-target directories and was added without targets, add it again with the targets it was built for", source.Path)
+	}
+
+	targets := make([]resolver.Target, 0, len(targetNames))
+	for _, target := range targetNames {
+		targets = append(targets, resolver.Target{
+			TargetName: target,
+			Link:       localSourceScheme + source.Path,
+			Hash:       source.Hash,
+		})
+	}
+
+	return resolver.ModVersion{
+		Version:          source.UPlugin.SemVersion,
+		GameVersion:      source.UPlugin.GameVersion,
+		Dependencies:     dependencies,
+		Targets:          targets,
+		RequiredOnRemote: true,
+	}, nil
+}
+
+// openModArchive returns the archive for a locked target, either from a local source or from the download cache
+func openModArchive(ctx context.Context, modReference string, version string, link string, hash string, target string, updates chan<- utils.GenericProgress, downloadSemaphore chan int) (*os.File, int64, error) {
+	if sourcePath, ok := strings.CutPrefix(link, localSourceScheme); ok {
+		return cache.OpenLocalSource(sourcePath, target) //nolint:wrapcheck
+	}
+
+	if archivePath, ok := strings.CutPrefix(link, provider.StaticFileScheme); ok {
+		return cache.OpenLocalArchive(archivePath, hash) //nolint:wrapcheck
+	}
+
+	return cache.DownloadOrCache(ctx, modReference+"_"+version+"_"+target+".zip", hash, registryTargetSize(modReference, version, target), link, updates, downloadSemaphore) //nolint:wrapcheck
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// CopyToMemory copies what describes the installation to a memory:// path, so that changes can be simulated on the copy.
+// Only the game executable, the version file, the lockfiles and the markers of the installed mods are copied:
+// the executable is left empty, and installed mods are recognized by their markers without copying their files.
+func (i *Installation) CopyToMemory(ctx context.Context, global *GlobalContext, memoryPath string) error {
+	parsed, err := url.Parse(memoryPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse path: %w", err)
+	}
+
+	if parsed.Scheme != "memory" {
+		return fmt.Errorf("not a memory:// path: %s", memoryPath)
+	}
+
+	platform, err := i.GetPlatform(ctx, global)
+	if err != nil {
+		return err
+	}
+
+	source, err := i.GetDisk()
+	if err != nil {
+		return err
+	}
+
+	target, err := disk.FromPath(memoryPath)
+	if err != nil {
+		return fmt.Errorf("failed to open memory disk: %w", err)
+	}
+
+	if err := target.MkDir(ctx, parsed.Path); err != nil {
+		return fmt.Errorf("failed to create %s: %w", parsed.Path, err)
+	}
+
+	// Only the existence of the executables is checked
+	for _, executable := range rootExecutables {
+		exists, err := source.Exists(ctx, filepath.Join(i.BasePath(), executable))
+		if err != nil {
+			return fmt.Errorf("failed reading %s: %w", executable, err)
+		}
+
+		if exists {
+			if err := target.Write(ctx, filepath.Join(parsed.Path, executable), []byte{}); err != nil {
+				return fmt.Errorf("failed to write %s: %w", executable, err)
+			}
+		}
+	}
+
+	if err := copyFile(ctx, source, target, i.BasePath(), parsed.Path, platform.VersionPath); err != nil {
+		return err
+	}
+
+	modsDirectory := filepath.Join(i.BasePath(), platform.LockfilePath)
+	exists, err := source.Exists(ctx, modsDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to check if %s exists: %w", modsDirectory, err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	entries, err := source.ReadDir(ctx, modsDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", modsDirectory, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "-lock.json") {
+			continue
+		}
+
+		if err := copyFile(ctx, source, target, i.BasePath(), parsed.Path, filepath.Join(platform.LockfilePath, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	existingMods, err := getExistingMods(ctx, source, modsDirectory)
+	if err != nil {
+		return err
+	}
+
+	for _, locations := range existingMods {
+		for location := range locations {
+			for _, marker := range []string{".smm", ".smm-manifest"} {
+				markerPath := filepath.Join(platform.LockfilePath, location, marker)
+				err := copyFile(ctx, source, target, i.BasePath(), parsed.Path, markerPath)
+				if err != nil && !errors.Is(err, errFileMissing) {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// planCount numbers the memory disks of plans, so that concurrent plans do not share their copies
+var planCount atomic.Int64
+
+// Plan applies the profile of the installation to a copy of it in memory, and returns the changes applying would make.
+// The mods which would be installed are downloaded to the cache, but the installation is left untouched,
+// and neither its hooks nor its history are involved.
+func (i *Installation) Plan(ctx context.Context, global *GlobalContext, options InstallOptions) (*HookChanges, error) {
+	planName := "plan-" + strconv.FormatInt(planCount.Add(1), 10)
+	planPath := "memory://" + planName + "/game"
+
+	// The copy is only used by this plan
+	defer disk.RemoveMemory(planName)
+
+	if err := i.CopyToMemory(ctx, global, planPath); err != nil {
+		return nil, fmt.Errorf("failed to copy installation: %w", err)
+	}
+
+	planned := &Installation{
+		Path:      planPath,
+		Profile:   i.Profile,
+		Name:      i.Name,
+		Variables: i.Variables,
+		Vanilla:   i.Vanilla,
+	}
+
+	d, err := planned.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	// Edited config files are detected when planning as well
+	source, err := i.GetDisk()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile := global.Profiles.GetProfile(i.Profile); profile != nil {
+		for configPath := range profile.Configs {
+			err := copyFile(ctx, source, d, i.BasePath(), planned.BasePath(), filepath.FromSlash(configPath))
+			if err != nil && !errors.Is(err, errFileMissing) {
+				return nil, err
+			}
+		}
+	}
+
+	platform, err := planned.GetPlatform(ctx, global)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect platform: %w", err)
+	}
+
+	changes := i.hookChanges(platform)
+
+	if _, err := planned.install(ctx, global, platform, &changes, options, nil); err != nil {
+		return nil, err
+	}
+
+	return &changes, nil
+}
+
+var errFileMissing = errors.New("file does not exist")
+
+// copyFile copies the file at the relative path from the base of the source disk to the base of the target disk
+func copyFile(ctx context.Context, source disk.Disk, target disk.Disk, sourceBase string, targetBase string, relativePath string) error {
+	sourcePath := filepath.Join(sourceBase, relativePath)
+
+	exists, err := source.Exists(ctx, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to check if %s exists: %w", sourcePath, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("failed to copy %s: %w", sourcePath, errFileMissing)
+	}
+
+	data, err := source.Read(ctx, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", sourcePath, err)
+	}
+
+	targetPath := filepath.Join(targetBase, relativePath)
+	if err := target.MkDir(ctx, filepath.Dir(targetPath)); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(targetPath), err)
+	}
+
+	if err := target.Write(ctx, targetPath, data); err != nil {
+		return fmt.Errorf("failed writing %s: %w", targetPath, err)
+	}
+
+	return nil
+}
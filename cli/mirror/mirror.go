@@ -0,0 +1,206 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/localregistry"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+const (
+	versionsPrefix = "/v1/mod/"
+	versionsSuffix = "/versions/all"
+	downloadPrefix = "/v1/mirror/"
+)
+
+// Server serves the local registry and download cache as an SMR-compatible API,
+// fetching from the upstream API (api-base) whenever something is missing locally
+type Server struct {
+	refreshed       map[string]time.Time
+	refreshInterval time.Duration
+	refreshedLock   sync.Mutex
+	offline         bool
+}
+
+// NewServer creates a mirror server.
+// Mod versions are re-fetched from upstream once they are older than the refresh interval,
+// unless the mirror is offline, in which case only local data is served.
+func NewServer(refreshInterval time.Duration, offline bool) *Server {
+	return &Server{
+		refreshed:       make(map[string]time.Time),
+		refreshInterval: refreshInterval,
+		offline:         offline,
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, versionsPrefix) && strings.HasSuffix(r.URL.Path, versionsSuffix):
+		modID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, versionsPrefix), versionsSuffix)
+		s.serveVersions(w, r, modID)
+	case strings.HasPrefix(r.URL.Path, downloadPrefix):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, downloadPrefix), "/")
+		if len(parts) != 3 {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		s.serveDownload(w, r, parts[0], parts[1], parts[2])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) serveVersions(w http.ResponseWriter, r *http.Request, modID string) {
+	versions, err := s.modVersions(r.Context(), modID)
+	if err != nil {
+		slog.Error("failed to get mod versions", slog.String("mod_reference", modID), slog.Any("err", err))
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	mirrored := make([]ficsit.ModVersion, len(versions))
+	for i, version := range versions {
+		targets := make([]ficsit.Target, len(version.Targets))
+		for j, target := range version.Targets {
+			target.Link = downloadLink(modID, version.Version, target.TargetName)
+			targets[j] = target
+		}
+		version.Targets = targets
+		mirrored[i] = version
+	}
+
+	writeJSON(w, http.StatusOK, ficsit.AllVersionsResponse{
+		Data:    mirrored,
+		Success: true,
+	})
+}
+
+// modVersions returns the versions of the mod from the local registry,
+// refreshing them from upstream if they are missing or stale
+func (s *Server) modVersions(ctx context.Context, modID string) ([]ficsit.ModVersion, error) {
+	s.refreshedLock.Lock()
+	lastRefresh, ok := s.refreshed[modID]
+	s.refreshedLock.Unlock()
+
+	if s.offline || (ok && time.Since(lastRefresh) < s.refreshInterval) {
+		return s.localModVersions(modID)
+	}
+
+	response, err := ficsit.GetAllModVersions(ctx, modID)
+	if err == nil && response.Error != nil {
+		err = fmt.Errorf("upstream error: %s", response.Error.Message)
+	}
+
+	if err != nil {
+		slog.Warn("failed to fetch mod versions from upstream, serving local registry", slog.String("mod_reference", modID), slog.Any("err", err))
+		return s.localModVersions(modID)
+	}
+
+	localregistry.Add(modID, response.Data)
+
+	s.refreshedLock.Lock()
+	s.refreshed[modID] = time.Now()
+	s.refreshedLock.Unlock()
+
+	return response.Data, nil
+}
+
+func (s *Server) localModVersions(modID string) ([]ficsit.ModVersion, error) {
+	versions, err := localregistry.GetModVersions(modID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local mod versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *Server) serveDownload(w http.ResponseWriter, r *http.Request, modID string, version string, targetName string) {
+	versions, err := localregistry.GetModVersions(modID)
+	if err != nil {
+		slog.Error("failed to get local mod versions", slog.String("mod_reference", modID), slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var target *ficsit.Target
+	for _, modVersion := range versions {
+		if modVersion.Version != version {
+			continue
+		}
+		for _, t := range modVersion.Targets {
+			if t.TargetName == targetName {
+				t := t
+				target = &t
+				break
+			}
+		}
+	}
+
+	if target == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s@%s has no target %s", modID, version, targetName))
+		return
+	}
+
+	link := target.Link
+	if !strings.Contains(link, "://") {
+		link = viper.GetString("api-base") + link
+	}
+
+	// Same cache key as installations, so the mirror shares the download cache with them
+	cacheKey := modID + "_" + version + "_" + targetName + ".zip"
+
+	slog.Info("serving mod", slog.String("mod_reference", modID), slog.String("version", version), slog.String("target", targetName))
+
+	f, _, err := cache.DownloadOrCache(r.Context(), cacheKey, target.Hash, target.Size, link, nil, nil)
+	if err != nil {
+		slog.Error("failed to download mod", slog.String("mod_reference", modID), slog.String("version", version), slog.Any("err", err))
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeContent(w, r, cacheKey, stat.ModTime(), f)
+}
+
+func downloadLink(modID string, version string, targetName string) string {
+	return downloadPrefix + url.PathEscape(modID) + "/" + url.PathEscape(version) + "/" + url.PathEscape(targetName)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("failed to write response", slog.Any("err", err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ficsit.AllVersionsResponse{
+		Error: &ficsit.Error{
+			Message: message,
+			Code:    int64(status),
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package mirror
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
+	"github.com/satisfactorymodding/ficsit-cli/cli/localregistry"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+func init() {
+	cfg.SetDefaults()
+}
+
+func testModZip(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	w, err := writer.Create("MirrorTestMod.uplugin")
+	testza.AssertNoError(t, err)
+	_, err = w.Write([]byte(`{"SemVersion": "1.0.0", "FriendlyName": "Mirror Test Mod"}`))
+	testza.AssertNoError(t, err)
+	testza.AssertNoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestMirror(t *testing.T) {
+	// Start from an empty cache, so the first download reaches upstream on every run
+	cacheDir := viper.GetString("cache-dir")
+	viper.Set("cache-dir", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache-dir", cacheDir)
+	})
+
+	testza.AssertNoError(t, localregistry.Init())
+	_, err := cache.LoadCacheMods()
+	testza.AssertNoError(t, err)
+
+	modZip := testModZip(t)
+	hash := sha256.Sum256(modZip)
+
+	var downloads atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/mod/MirrorTestMod/versions/all":
+			_ = json.NewEncoder(w).Encode(ficsit.AllVersionsResponse{
+				Success: true,
+				Data: []ficsit.ModVersion{
+					{
+						ID:      "mirror-test-version",
+						Version: "1.0.0",
+						Targets: []ficsit.Target{
+							{
+								TargetName: "Windows",
+								Link:       "/v1/version/mirror-test-version/Windows/download",
+								Hash:       hex.EncodeToString(hash[:]),
+								Size:       int64(len(modZip)),
+							},
+						},
+					},
+				},
+			})
+		case "/v1/version/mirror-test-version/Windows/download":
+			if r.Method == http.MethodGet {
+				downloads.Add(1)
+			}
+			_, _ = w.Write(modZip)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	apiBase := viper.GetString("api-base")
+	viper.Set("api-base", upstream.URL)
+	defer viper.Set("api-base", apiBase)
+
+	mirror := httptest.NewServer(NewServer(time.Minute, false))
+	defer mirror.Close()
+
+	response, err := http.Get(mirror.URL + "/v1/mod/MirrorTestMod/versions/all")
+	testza.AssertNoError(t, err)
+	defer response.Body.Close()
+
+	var versions ficsit.AllVersionsResponse
+	testza.AssertNoError(t, json.NewDecoder(response.Body).Decode(&versions))
+	testza.AssertLen(t, versions.Data, 1)
+	testza.AssertEqual(t, "/v1/mirror/MirrorTestMod/1.0.0/Windows", versions.Data[0].Targets[0].Link)
+
+	for i := 0; i < 2; i++ {
+		download, err := http.Get(mirror.URL + versions.Data[0].Targets[0].Link)
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, http.StatusOK, download.StatusCode)
+
+		body, err := io.ReadAll(download.Body)
+		download.Body.Close()
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, modZip, body)
+	}
+
+	// The second download is served from the cache
+	testza.AssertEqual(t, int32(1), downloads.Load())
+
+	// Versions keep being served from the local registry if upstream goes away
+	upstream.Close()
+
+	offline := httptest.NewServer(NewServer(0, false))
+	defer offline.Close()
+
+	response, err = http.Get(offline.URL + "/v1/mod/MirrorTestMod/versions/all")
+	testza.AssertNoError(t, err)
+	defer response.Body.Close()
+
+	testza.AssertNoError(t, json.NewDecoder(response.Body).Decode(&versions))
+	testza.AssertLen(t, versions.Data, 1)
+}
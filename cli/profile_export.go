@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// profileFile is the format of exported profiles
+type profileFile struct {
+	Profile *Profile        `json:"profile"`
+	Version ProfilesVersion `json:"version"`
+}
+
+// ExportProfile writes the profile to a file which can be imported by other users
+func (p *Profiles) ExportProfile(name string, filePath string) error {
+	profile := p.GetProfile(name)
+	if profile == nil {
+		return fmt.Errorf("profile with name %s does not exist", name)
+	}
+
+	if sideloaded := sideloadedMods(profile); len(sideloaded) > 0 {
+		return fmt.Errorf("mods installed from local sources can not be exported, remove them first: %s", strings.Join(sideloaded, ", "))
+	}
+
+	data, err := json.MarshalIndent(profileFile{
+		Profile: profile,
+		Version: nextProfilesVersion - 1,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write profile file: %w", err)
+	}
+
+	return nil
+}
+
+// ImportProfile adds the profile exported to the file, under the name if given, or else under its exported name
+func (p *Profiles) ImportProfile(filePath string, name string) (*Profile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	var file profileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profile file: %w", err)
+	}
+
+	if file.Profile == nil {
+		return nil, errors.New("file does not contain a profile")
+	}
+
+	if file.Version >= nextProfilesVersion {
+		return nil, fmt.Errorf("unknown profile file version: %d", file.Version)
+	}
+
+	imported := file.Profile
+	if name != "" {
+		imported.Name = name
+	}
+
+	if imported.Name == "" {
+		return nil, errors.New("profile has no name")
+	}
+
+	for reference, mod := range imported.Mods {
+		if !utils.SemVerRegex.MatchString(mod.Version) {
+			return nil, fmt.Errorf("invalid version %s of %s", mod.Version, reference)
+		}
+	}
+
+	for _, target := range imported.RequiredTargets {
+		if _, err := ParseTargetName(string(target)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sources are paths on the machine which exported the profile
+	if sideloaded := sideloadedMods(imported); len(sideloaded) > 0 {
+		return nil, fmt.Errorf("profile contains mods installed from local sources: %s", strings.Join(sideloaded, ", "))
+	}
+
+	if imported.Configs != nil {
+		configs := make(map[string]ProfileConfig, len(imported.Configs))
+		for configPath, config := range imported.Configs {
+			cleaned, err := cleanConfigPath(configPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := configs[cleaned]; ok {
+				return nil, fmt.Errorf("profile contains config %s more than once", cleaned)
+			}
+
+			configs[cleaned] = config
+		}
+		imported.Configs = configs
+	}
+
+	if p.GetProfile(imported.Name) != nil {
+		return nil, fmt.Errorf("profile with name %s already exists", imported.Name)
+	}
+
+	p.Profiles[imported.Name] = imported
+
+	return imported, nil
+}
+
+// sideloadedMods returns the sorted references of the mods of the profile which are installed from a local source
+func sideloadedMods(profile *Profile) []string {
+	var sideloaded []string
+	for reference, mod := range profile.Mods {
+		if mod.Source != "" {
+			sideloaded = append(sideloaded, reference)
+		}
+	}
+
+	slices.Sort(sideloaded)
+
+	return sideloaded
+}
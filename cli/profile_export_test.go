@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestImportProfile(t *testing.T) {
+	profiles := &Profiles{Profiles: map[string]*Profile{}}
+	dir := t.TempDir()
+
+	// Local sources are paths on this machine
+	sideloaded, err := profiles.AddProfile("Sideloaded")
+	testza.AssertNoError(t, err)
+	sideloaded.Mods = map[string]ProfileMod{
+		"AreaActions": {Version: ">=1.0.0", Enabled: true},
+		"LocalMod":    {Version: "1.0.0", Source: "/home/user/LocalMod", Enabled: true},
+	}
+
+	err = profiles.ExportProfile("Sideloaded", filepath.Join(dir, "sideloaded.json"))
+	testza.AssertNotNil(t, err)
+	testza.AssertContains(t, err.Error(), "LocalMod")
+
+	writeProfileFile := func(name string, content string) string {
+		filePath := filepath.Join(dir, name)
+		testza.AssertNoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+		return filePath
+	}
+
+	_, err = profiles.ImportProfile(writeProfileFile("source.json", `{"profile": {"name": "Source", "mods": {"LocalMod": {"version": "1.0.0", "source": "C:\\Mods\\LocalMod.zip", "enabled": true}}}}`), "")
+	testza.AssertNotNil(t, err)
+	testza.AssertNil(t, profiles.GetProfile("Source"))
+
+	// Config paths with either separator are the same file
+	_, err = profiles.ImportProfile(writeProfileFile("duplicate.json", `{"profile": {"name": "Duplicate", "configs": {
+		"FactoryGame/Configs/Mod.cfg": {"content": "a"},
+		"FactoryGame\\Configs\\Mod.cfg": {"content": "b"}
+	}}}`), "")
+	testza.AssertNotNil(t, err)
+	testza.AssertNil(t, profiles.GetProfile("Duplicate"))
+
+	imported, err := profiles.ImportProfile(writeProfileFile("configs.json", `{"profile": {"name": "Configs", "configs": {
+		"FactoryGame\\Configs\\Mod.cfg": {"content": "a"},
+		"FactoryGame/Saved/Game.ini": {"content": "b", "template": true}
+	}}}`), "")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, map[string]ProfileConfig{
+		"FactoryGame/Configs/Mod.cfg": {Content: "a"},
+		"FactoryGame/Saved/Game.ini":  {Content: "b", Template: true},
+	}, imported.Configs)
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
@@ -44,14 +45,24 @@ type Profiles struct {
 }
 
 type Profile struct {
-	Mods            map[string]ProfileMod `json:"mods"`
-	Name            string                `json:"name"`
-	RequiredTargets []resolver.TargetName `json:"required_targets"`
+	Mods                 map[string]ProfileMod    `json:"mods"`
+	Configs              map[string]ProfileConfig `json:"configs,omitempty"`
+	Name                 string                   `json:"name"`
+	RequiredTargets      []resolver.TargetName    `json:"required_targets"`
+	OptionalDependencies OptionalDependencyPolicy `json:"optional_dependencies,omitempty"`
+	ExcludedMods         []string                 `json:"excluded_mods,omitempty"`
 }
 
 type ProfileMod struct {
-	Version string `json:"version"`
-	Enabled bool   `json:"enabled"`
+	Version              string                   `json:"version"`
+	OptionalDependencies OptionalDependencyPolicy `json:"optional_dependencies,omitempty"`
+
+	// Source is the path of a local archive or directory installed instead of the repository versions
+	Source string `json:"source,omitempty"`
+
+	// SourceTargets are the targets a local source without per-target directories was built for
+	SourceTargets []resolver.TargetName `json:"source_targets,omitempty"`
+	Enabled       bool                  `json:"enabled"`
 }
 
 func InitProfiles() (*Profiles, error) {
@@ -258,8 +269,9 @@ func (p *Profile) AddMod(reference string, version string) error {
 	}
 
 	p.Mods[reference] = ProfileMod{
-		Version: version,
-		Enabled: true,
+		Version:              version,
+		OptionalDependencies: p.Mods[reference].OptionalDependencies,
+		Enabled:              true,
 	}
 
 	return nil
@@ -290,7 +302,7 @@ func (p *Profile) HasMod(reference string) bool {
 // An optional lockfile can be passed if one exists.
 //
 // Returns an error if resolution is impossible.
-func (p *Profile) Resolve(resolver resolver.DependencyResolver, lockFile *resolver.LockFile, gameVersion int) (*resolver.LockFile, error) {
+func (p *Profile) Resolve(provider resolver.Provider, lockFile *resolver.LockFile, gameVersion int) (*resolver.LockFile, error) {
 	toResolve := make(map[string]string)
 	for modReference, mod := range p.Mods {
 		if mod.Enabled {
@@ -298,7 +310,9 @@ func (p *Profile) Resolve(resolver resolver.DependencyResolver, lockFile *resolv
 		}
 	}
 
-	resultLockfile, err := resolver.ResolveModDependencies(toResolve, lockFile, gameVersion, p.RequiredTargets)
+	depResolver := resolver.NewDependencyResolver(newProfileProvider(provider, p, gameVersion))
+
+	resultLockfile, err := depResolver.ResolveModDependencies(toResolve, lockFile, gameVersion, p.RequiredTargets)
 	if err != nil {
 		return nil, fmt.Errorf("failed resolving profile dependencies: %w", err)
 	}
@@ -327,8 +341,117 @@ func (p *Profile) SetModEnabled(reference string, enabled bool) {
 		return
 	}
 
-	p.Mods[reference] = ProfileMod{
-		Version: p.Mods[reference].Version,
-		Enabled: enabled,
+	mod := p.Mods[reference]
+	mod.Enabled = enabled
+	p.Mods[reference] = mod
+}
+
+// HasRequiredTarget returns true if the profile requires mods to support the given target.
+func (p *Profile) HasRequiredTarget(target resolver.TargetName) bool {
+	return slices.Contains(p.RequiredTargets, target)
+}
+
+// AddRequiredTargets adds the given targets to the required targets of the profile.
+func (p *Profile) AddRequiredTargets(targets ...resolver.TargetName) error {
+	return p.SetRequiredTargets(append(slices.Clone(p.RequiredTargets), targets...)...)
+}
+
+// RemoveRequiredTargets removes the given targets from the required targets of the profile.
+func (p *Profile) RemoveRequiredTargets(targets ...resolver.TargetName) {
+	p.RequiredTargets = slices.DeleteFunc(p.RequiredTargets, func(target resolver.TargetName) bool {
+		return slices.Contains(targets, target)
+	})
+}
+
+// SetRequiredTargets replaces the required targets of the profile.
+//
+// Returns an error if any of the targets is unknown.
+func (p *Profile) SetRequiredTargets(targets ...resolver.TargetName) error {
+	for _, target := range targets {
+		if !slices.Contains(AllTargets, target) {
+			return fmt.Errorf("unknown target: %s", target)
+		}
 	}
+
+	requiredTargets := make([]resolver.TargetName, 0, len(AllTargets))
+	for _, target := range AllTargets {
+		if slices.Contains(targets, target) {
+			requiredTargets = append(requiredTargets, target)
+		}
+	}
+
+	p.RequiredTargets = requiredTargets
+
+	return nil
+}
+
+// ToggleRequiredTarget adds the target to the required targets if missing, or removes it otherwise.
+func (p *Profile) ToggleRequiredTarget(target resolver.TargetName) error {
+	if p.HasRequiredTarget(target) {
+		p.RemoveRequiredTargets(target)
+		return nil
+	}
+
+	return p.AddRequiredTargets(target)
+}
+
+// SetOptionalDependencies sets whether optional dependencies of all mods should be installed.
+func (p *Profile) SetOptionalDependencies(policy OptionalDependencyPolicy) {
+	p.OptionalDependencies = policy
+}
+
+// SetModOptionalDependencies overrides whether optional dependencies of the given mod should be installed.
+//
+// OptionalDependenciesDefault removes the override.
+func (p *Profile) SetModOptionalDependencies(reference string, policy OptionalDependencyPolicy) error {
+	if !p.HasMod(reference) {
+		return fmt.Errorf("mod %s is not part of the profile", reference)
+	}
+
+	mod := p.Mods[reference]
+	mod.OptionalDependencies = policy
+	p.Mods[reference] = mod
+
+	return nil
+}
+
+func (p *Profile) optionalDependencyPolicyFor(reference string) OptionalDependencyPolicy {
+	if mod, ok := p.Mods[reference]; ok && mod.OptionalDependencies != OptionalDependenciesDefault {
+		return mod.OptionalDependencies
+	}
+
+	if p.OptionalDependencies == OptionalDependenciesDefault {
+		return OptionalDependenciesSkip
+	}
+
+	return p.OptionalDependencies
+}
+
+// ExcludeMods prevents the given mods from being installed, even as optional dependencies.
+func (p *Profile) ExcludeMods(references ...string) error {
+	for _, reference := range references {
+		if p.HasMod(reference) {
+			return fmt.Errorf("mod %s is part of the profile and cannot be excluded", reference)
+		}
+	}
+
+	for _, reference := range references {
+		if !p.IsModExcluded(reference) {
+			p.ExcludedMods = append(p.ExcludedMods, reference)
+		}
+	}
+
+	return nil
+}
+
+// IncludeMods removes the given mods from the exclusion list.
+func (p *Profile) IncludeMods(references ...string) {
+	p.ExcludedMods = slices.DeleteFunc(p.ExcludedMods, func(reference string) bool {
+		return slices.Contains(references, reference)
+	})
+}
+
+// IsModExcluded returns true if the mod must never be installed with this profile.
+func (p *Profile) IsModExcluded(reference string) bool {
+	return slices.Contains(p.ExcludedMods, reference)
 }
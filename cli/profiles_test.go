@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"archive/zip"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/MarvinJWendt/testza"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
 	"github.com/satisfactorymodding/ficsit-cli/cfg"
 )
@@ -17,3 +23,158 @@ func TestProfilesInit(t *testing.T) {
 	testza.AssertNoError(t, err)
 	testza.AssertNotNil(t, profiles)
 }
+
+func TestProfileRequiredTargets(t *testing.T) {
+	profile := &Profile{Name: "TargetsTest"}
+
+	testza.AssertNoError(t, profile.AddRequiredTargets(resolver.TargetNameLinuxServer, resolver.TargetNameWindows))
+	testza.AssertEqual(t, []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameLinuxServer}, profile.RequiredTargets)
+
+	testza.AssertNoError(t, profile.AddRequiredTargets(resolver.TargetNameWindows))
+	testza.AssertLen(t, profile.RequiredTargets, 2)
+
+	testza.AssertNotNil(t, profile.AddRequiredTargets("Mac"))
+
+	profile.RemoveRequiredTargets(resolver.TargetNameWindows)
+	testza.AssertEqual(t, []resolver.TargetName{resolver.TargetNameLinuxServer}, profile.RequiredTargets)
+
+	testza.AssertNoError(t, profile.ToggleRequiredTarget(resolver.TargetNameWindowsServer))
+	testza.AssertTrue(t, profile.HasRequiredTarget(resolver.TargetNameWindowsServer))
+
+	testza.AssertNoError(t, profile.SetRequiredTargets())
+	testza.AssertLen(t, profile.RequiredTargets, 0)
+
+	target, err := ParseTargetName("windowsserver")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, resolver.TargetNameWindowsServer, target)
+}
+
+func TestProfileSkippedForTargets(t *testing.T) {
+	profile := &Profile{Name: "SkippedTargetsTest"}
+	testza.AssertNoError(t, profile.AddMod("ClientOnlyMod", "<=0.0.1"))
+	testza.AssertNoError(t, profile.SetRequiredTargets(resolver.TargetNameWindowsServer))
+
+	_, err := profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNotNil(t, err)
+
+	skipped, err := profile.SkippedForTargets(context.Background(), MockProvider{})
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, skipped, 1)
+	testza.AssertEqual(t, "ClientOnlyMod", skipped[0].ModReference)
+	testza.AssertEqual(t, []resolver.TargetName{resolver.TargetNameWindowsServer}, skipped[0].MissingTargets)
+
+	testza.AssertNoError(t, profile.SetRequiredTargets(resolver.TargetNameWindows, resolver.TargetNameWindowsServer))
+
+	lockFile, err := profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+
+	excluded := profile.ExcludedFromTargets(lockFile)
+	testza.AssertLen(t, excluded, 1)
+	testza.AssertEqual(t, "ClientOnlyMod", excluded[0].ModReference)
+	testza.AssertFalse(t, excluded[0].Skipped)
+}
+
+func TestProfileOptionalDependencies(t *testing.T) {
+	profile := &Profile{Name: "OptionalDependenciesTest"}
+	testza.AssertNoError(t, profile.AddMod("OptionalIntegrationMod", "1.0.0"))
+
+	lockFile, err := profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", lockFile.Mods["ModularUI"].Version)
+
+	tree, err := profile.DependencyTree(context.Background(), MockProvider{}, lockFile, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, tree, 1)
+	testza.AssertLen(t, tree[0].Dependencies, 2)
+	testza.AssertEqual(t, "ModularUI", tree[0].Dependencies[0].ModReference)
+	testza.AssertEqual(t, DependencyStatusSkipped, tree[0].Dependencies[0].Status)
+
+	profile.SetOptionalDependencies(OptionalDependenciesInstall)
+
+	lockFile, err = profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "2.1.12", lockFile.Mods["ModularUI"].Version)
+
+	tree, err = profile.DependencyTree(context.Background(), MockProvider{}, lockFile, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, DependencyStatusInstalled, tree[0].Dependencies[0].Status)
+
+	testza.AssertNoError(t, profile.SetModOptionalDependencies("OptionalIntegrationMod", OptionalDependenciesSkip))
+
+	lockFile, err = profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", lockFile.Mods["ModularUI"].Version)
+
+	testza.AssertNoError(t, profile.SetModOptionalDependencies("OptionalIntegrationMod", OptionalDependenciesDefault))
+	testza.AssertNoError(t, profile.ExcludeMods("ModularUI"))
+	testza.AssertNotNil(t, profile.ExcludeMods("OptionalIntegrationMod"))
+
+	lockFile, err = profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", lockFile.Mods["ModularUI"].Version)
+
+	tree, err = profile.DependencyTree(context.Background(), MockProvider{}, lockFile, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "excluded by profile", tree[0].Dependencies[0].Reason)
+
+	// Optional dependencies which cannot be installed do not prevent installing the mod
+	unavailable := &Profile{Name: "UnavailableDependenciesTest", OptionalDependencies: OptionalDependenciesInstall}
+	testza.AssertNoError(t, unavailable.AddMod("UnavailableIntegrationMod", "1.0.0"))
+
+	lockFile, err = unavailable.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.0.0", lockFile.Mods["UnavailableIntegrationMod"].Version)
+	testza.AssertEqual(t, "", lockFile.Mods["ModularUI"].Version)
+
+	tree, err = unavailable.DependencyTree(context.Background(), MockProvider{}, lockFile, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, tree[0].Dependencies, 3)
+	testza.AssertEqual(t, "optional dependency cannot be installed: no version matches ^9.0.0", tree[0].Dependencies[0].Reason)
+	testza.AssertEqual(t, "optional dependency cannot be installed: not found in any repository", tree[0].Dependencies[1].Reason)
+}
+
+func TestProfileLocalSource(t *testing.T) {
+	sourceDir := t.TempDir()
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(sourceDir, "AreaActions.uplugin"), []byte(`{
+		"SemVersion": "1.7.0-dev",
+		"Plugins": [
+			{ "Name": "SML", "SemVersion": "^3.6.0", "Enabled": true },
+			{ "Name": "EnhancedInput", "Enabled": true }
+		]
+	}`), 0o600))
+
+	profile := &Profile{Name: "LocalSourceTest"}
+
+	// Builds without per-target directories need the targets they were built for
+	_, err := profile.AddLocalMod(sourceDir, ">=0.0.0")
+	testza.AssertNotNil(t, err)
+	testza.AssertFalse(t, profile.HasMod("AreaActions"))
+
+	reference, err := profile.AddLocalMod(sourceDir, ">=0.0.0", resolver.TargetNameWindows, resolver.TargetNameLinuxServer)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "AreaActions", reference)
+
+	lockFile, err := profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.7.0-dev", lockFile.Mods["AreaActions"].Version)
+	testza.AssertNotEqual(t, "", lockFile.Mods["SML"].Version)
+	testza.AssertEqual(t, localSourceScheme+sourceDir, lockFile.Mods["AreaActions"].Targets["LinuxServer"].Link)
+	testza.AssertNotEqual(t, "", lockFile.Mods["AreaActions"].Targets["LinuxServer"].Hash)
+	testza.AssertLen(t, lockFile.Mods["AreaActions"].Targets, 2)
+
+	archive, size, err := openModArchive(context.Background(), "AreaActions", "1.7.0-dev", lockFile.Mods["AreaActions"].Targets["Windows"].Link, "", "Windows", nil, nil)
+	testza.AssertNoError(t, err)
+	defer archive.Close()
+
+	zipReader, err := zip.NewReader(archive, size)
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, zipReader.File, 1)
+	testza.AssertEqual(t, "AreaActions.uplugin", zipReader.File[0].Name)
+
+	// Replacing the mod from the repository drops the local source
+	testza.AssertNoError(t, profile.AddMod("AreaActions", ">=0.0.0"))
+
+	lockFile, err = profile.Resolve(MockProvider{}, nil, math.MaxInt)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.6.7", lockFile.Mods["AreaActions"].Version)
+}
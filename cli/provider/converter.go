@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"strings"
+
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
 	"github.com/spf13/viper"
 
@@ -23,7 +25,7 @@ func convertFicsitVersionsToResolver(versions []ficsit.ModVersion) []resolver.Mo
 		for j, target := range modVersion.Targets {
 			targets[j] = resolver.Target{
 				TargetName: resolver.TargetName(target.TargetName),
-				Link:       viper.GetString("api-base") + target.Link,
+				Link:       resolveTargetLink(target.Link),
 				Hash:       target.Hash,
 				Size:       target.Size,
 			}
@@ -39,3 +41,11 @@ func convertFicsitVersionsToResolver(versions []ficsit.ModVersion) []resolver.Mo
 	}
 	return modVersions
 }
+
+// resolveTargetLink prefixes links relative to the API with its base URL
+func resolveTargetLink(link string) string {
+	if strings.Contains(link, "://") {
+		return link
+	}
+	return viper.GetString("api-base") + link
+}
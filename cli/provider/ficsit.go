@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/Khan/genqlient/graphql"
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
@@ -29,13 +31,16 @@ func (p FicsitProvider) GetMod(context context.Context, modReference string) (*f
 	return ficsit.GetMod(context, p.client, modReference)
 }
 
-func (p FicsitProvider) ModVersionsWithDependencies(_ context.Context, modID string) ([]resolver.ModVersion, error) {
-	response, err := ficsit.GetAllModVersions(modID)
+func (p FicsitProvider) ModVersionsWithDependencies(context context.Context, modID string) ([]resolver.ModVersion, error) {
+	response, err := ficsit.GetAllModVersions(context, modID)
 	if err != nil {
 		return nil, err
 	}
 
 	if response.Error != nil {
+		if response.Error.Code == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrModNotFound, modID)
+		}
 		return nil, errors.New(response.Error.Message)
 	}
 
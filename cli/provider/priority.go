@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+// RepositorySMR is the name of the SMR repository in the configured repository list
+const RepositorySMR = "smr"
+
+var errNoRepositories = errors.New("no repositories configured")
+
+// PriorityProvider combines multiple repositories in priority order.
+// Each mod is served by the first repository which contains it.
+type PriorityProvider struct {
+	providers []Provider
+}
+
+func NewPriorityProvider(providers ...Provider) PriorityProvider {
+	return PriorityProvider{
+		providers: providers,
+	}
+}
+
+// InitRepositoryProvider creates a provider for the configured repositories, in priority order.
+// Repositories are local directories or HTTP base URLs of static repositories, or "smr" for the provided SMR provider.
+// SMR is used with the lowest priority unless it is listed explicitly.
+func InitRepositoryProvider(repositories []string, smrProvider Provider) Provider {
+	if len(repositories) == 0 {
+		return smrProvider
+	}
+
+	providers := make([]Provider, 0, len(repositories)+1)
+	hasSMR := false
+	for _, repository := range repositories {
+		if repository == RepositorySMR {
+			providers = append(providers, smrProvider)
+			hasSMR = true
+			continue
+		}
+
+		providers = append(providers, NewStaticProvider(repository))
+	}
+
+	if !hasSMR {
+		providers = append(providers, smrProvider)
+	}
+
+	return NewPriorityProvider(providers...)
+}
+
+func (p PriorityProvider) Mods(context context.Context, filter ficsit.ModFilter) (*ficsit.ModsResponse, error) {
+	seen := make(map[string]bool)
+	mods := make([]ficsit.ModsModsGetModsModsMod, 0)
+
+	// Mods listed by several repositories are counted once for each of them
+	count := 0
+	for _, provider := range p.providers {
+		response, err := provider.Mods(context, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list mods from repository: %w", err)
+		}
+
+		count += response.Mods.Count
+
+		for _, mod := range response.Mods.Mods {
+			if seen[mod.Mod_reference] {
+				continue
+			}
+			seen[mod.Mod_reference] = true
+			mods = append(mods, mod)
+		}
+	}
+
+	return &ficsit.ModsResponse{
+		Mods: ficsit.ModsModsGetMods{
+			Count: count,
+			Mods:  mods,
+		},
+	}, nil
+}
+
+// Only mods missing from a repository are looked up in the next one,
+// other errors mean the repository could not be asked and are returned.
+
+func (p PriorityProvider) GetMod(context context.Context, modReference string) (*ficsit.GetModResponse, error) {
+	lastErr := errNoRepositories
+	for _, provider := range p.providers {
+		response, err := provider.GetMod(context, modReference)
+		if err == nil {
+			return response, nil
+		}
+		if !errors.Is(err, ErrModNotFound) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (p PriorityProvider) ModVersionsWithDependencies(context context.Context, modID string) ([]resolver.ModVersion, error) {
+	var lastErr error
+	for _, provider := range p.providers {
+		versions, err := provider.ModVersionsWithDependencies(context, modID)
+		if err != nil {
+			if !errors.Is(err, ErrModNotFound) {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+		if len(versions) > 0 {
+			return versions, nil
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return []resolver.ModVersion{}, nil
+}
+
+func (p PriorityProvider) GetModName(context context.Context, modReference string) (*resolver.ModName, error) {
+	lastErr := errNoRepositories
+	for _, provider := range p.providers {
+		name, err := provider.GetModName(context, modReference)
+		if err == nil {
+			return name, nil
+		}
+		if !errors.Is(err, ErrModNotFound) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (p PriorityProvider) IsOffline() bool {
+	return false
+}
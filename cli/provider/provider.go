@@ -2,12 +2,17 @@ package provider
 
 import (
 	"context"
+	"errors"
 
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
 	"github.com/satisfactorymodding/ficsit-cli/ficsit"
 )
 
+// ErrModNotFound is returned when a repository does not contain a mod.
+// Other errors mean the repository could not be asked.
+var ErrModNotFound = errors.New("mod not found")
+
 type Provider interface {
 	resolver.Provider
 	Mods(context context.Context, filter ficsit.ModFilter) (*ficsit.ModsResponse, error)
@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/localregistry"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// StaticIndexFile is the name of the index file at the root of a static repository
+const StaticIndexFile = "index.json"
+
+// StaticFileScheme prefixes the links of archives in a static repository on this machine
+const StaticFileScheme = "file://"
+
+// StaticIndex is the index of a static repository
+//
+// Target links are relative to the root of the repository, unless they are absolute URLs
+type StaticIndex struct {
+	Mods map[string]StaticMod `json:"mods"`
+}
+
+type StaticMod struct {
+	Name             string              `json:"name"`
+	ShortDescription string              `json:"short_description"`
+	FullDescription  string              `json:"full_description"`
+	SourceURL        string              `json:"source_url"`
+	Authors          []string            `json:"authors"`
+	Versions         []ficsit.ModVersion `json:"versions"`
+}
+
+// StaticProvider reads mods from a static repository,
+// either a local directory or an HTTP base URL, containing an index and the mod archives
+type StaticProvider struct {
+	index    *staticIndexState
+	location string
+}
+
+// staticIndexState keeps the index once it was loaded successfully,
+// failures are not kept so that a later request can load it again
+type staticIndexState struct {
+	index *StaticIndex
+	mu    sync.Mutex
+}
+
+func NewStaticProvider(location string) StaticProvider {
+	return StaticProvider{
+		location: strings.TrimSuffix(location, "/"),
+		index:    &staticIndexState{},
+	}
+}
+
+func (p StaticProvider) isRemote() bool {
+	return strings.HasPrefix(p.location, "http://") || strings.HasPrefix(p.location, "https://")
+}
+
+func (p StaticProvider) getIndex(ctx context.Context) (*StaticIndex, error) {
+	p.index.mu.Lock()
+	defer p.index.mu.Unlock()
+
+	if p.index.index != nil {
+		return p.index.index, nil
+	}
+
+	index, err := p.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.index.index = index
+
+	return index, nil
+}
+
+func (p StaticProvider) loadIndex(ctx context.Context) (*StaticIndex, error) {
+	var reader io.ReadCloser
+	if p.isRemote() {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.location+"/"+StaticIndexFile, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed creating repository index request: %w", err)
+		}
+
+		response, err := utils.HTTPClient().Do(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repository index: %w", err)
+		}
+
+		if response.StatusCode != 200 {
+			response.Body.Close()
+			return nil, fmt.Errorf("failed to fetch repository index: %s", response.Status)
+		}
+
+		reader = response.Body
+	} else {
+		f, err := os.Open(filepath.Join(p.location, StaticIndexFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open repository index: %w", err)
+		}
+
+		reader = f
+	}
+	defer reader.Close()
+
+	var index StaticIndex
+	if err := json.NewDecoder(reader).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to decode repository index %s: %w", p.location, err)
+	}
+
+	return &index, nil
+}
+
+// resolveLink makes target links relative to the repository absolute
+func (p StaticProvider) resolveLink(link string) (string, error) {
+	if strings.Contains(link, "://") {
+		return link, nil
+	}
+
+	if p.isRemote() {
+		return p.location + "/" + strings.TrimPrefix(link, "/"), nil
+	}
+
+	location, err := filepath.Abs(filepath.Join(p.location, filepath.FromSlash(link)))
+	if err != nil {
+		return "", fmt.Errorf("could not resolve absolute path of: %s: %w", link, err)
+	}
+
+	return StaticFileScheme + location, nil
+}
+
+func (p StaticProvider) getMod(ctx context.Context, modReference string) (*StaticMod, error) {
+	index, err := p.getIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, ok := index.Mods[modReference]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in repository %s", ErrModNotFound, modReference, p.location)
+	}
+
+	return &mod, nil
+}
+
+func (p StaticProvider) Mods(context context.Context, filter ficsit.ModFilter) (*ficsit.ModsResponse, error) {
+	index, err := p.getIndex(context)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make([]string, 0, len(index.Mods))
+	for modReference := range index.Mods {
+		references = append(references, modReference)
+	}
+	sort.Strings(references)
+
+	mods := make([]ficsit.ModsModsGetModsModsMod, 0)
+	for _, modReference := range references {
+		mod := index.Mods[modReference]
+
+		if len(filter.References) > 0 && !slices.Contains(filter.References, modReference) {
+			continue
+		}
+
+		if filter.Search != "" && !strings.Contains(strings.ToLower(mod.Name), strings.ToLower(filter.Search)) && !strings.Contains(strings.ToLower(modReference), strings.ToLower(filter.Search)) {
+			continue
+		}
+
+		mods = append(mods, ficsit.ModsModsGetModsModsMod{
+			Id:                modReference,
+			Name:              mod.Name,
+			Mod_reference:     modReference,
+			Last_version_date: time.Now(),
+			Created_at:        time.Now(),
+		})
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = 25
+	}
+
+	// Count is the number of matching mods on all pages
+	count := len(mods)
+
+	low := filter.Offset
+	high := filter.Offset + filter.Limit
+
+	if low > len(mods) {
+		return &ficsit.ModsResponse{
+			Mods: ficsit.ModsModsGetMods{
+				Count: count,
+				Mods:  []ficsit.ModsModsGetModsModsMod{},
+			},
+		}, nil
+	}
+
+	if high > len(mods) {
+		high = len(mods)
+	}
+
+	mods = mods[low:high]
+
+	return &ficsit.ModsResponse{
+		Mods: ficsit.ModsModsGetMods{
+			Count: count,
+			Mods:  mods,
+		},
+	}, nil
+}
+
+func (p StaticProvider) GetMod(context context.Context, modReference string) (*ficsit.GetModResponse, error) {
+	mod, err := p.getMod(context, modReference)
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make([]ficsit.GetModModAuthorsUserMod, 0, len(mod.Authors))
+	for _, author := range mod.Authors {
+		authors = append(authors, ficsit.GetModModAuthorsUserMod{
+			Role: "Unknown",
+			User: ficsit.GetModModAuthorsUserModUser{
+				Username: author,
+			},
+		})
+	}
+
+	return &ficsit.GetModResponse{
+		Mod: ficsit.GetModMod{
+			Id:               modReference,
+			Name:             mod.Name,
+			Mod_reference:    modReference,
+			Created_at:       time.Now(),
+			Authors:          authors,
+			Full_description: mod.FullDescription,
+			Source_url:       mod.SourceURL,
+		},
+	}, nil
+}
+
+func (p StaticProvider) ModVersionsWithDependencies(context context.Context, modID string) ([]resolver.ModVersion, error) {
+	mod, err := p.getMod(context, modID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]ficsit.ModVersion, len(mod.Versions))
+	for i, version := range mod.Versions {
+		if version.ID == "" {
+			version.ID = modID + "@" + version.Version
+		}
+
+		targets := make([]ficsit.Target, len(version.Targets))
+		for j, target := range version.Targets {
+			target.Link, err = p.resolveLink(target.Link)
+			if err != nil {
+				return nil, err
+			}
+			targets[j] = target
+		}
+		version.Targets = targets
+
+		versions[i] = version
+	}
+
+	localregistry.Add(modID, versions)
+
+	return convertFicsitVersionsToResolver(versions), nil
+}
+
+func (p StaticProvider) GetModName(context context.Context, modReference string) (*resolver.ModName, error) {
+	mod, err := p.getMod(context, modReference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolver.ModName{
+		ID:           modReference,
+		Name:         mod.Name,
+		ModReference: modReference,
+	}, nil
+}
+
+func (p StaticProvider) IsOffline() bool {
+	return false
+}
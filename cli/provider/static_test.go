@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/cli/localregistry"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+func init() {
+	cfg.SetDefaults()
+}
+
+const testIndex = `{
+	"mods": {
+		"InternalMod": {
+			"name": "Internal Mod",
+			"authors": ["Ficsit"],
+			"versions": [
+				{
+					"version": "1.0.0",
+					"dependencies": [{ "mod_id": "SML", "condition": "^3.6.0" }],
+					"targets": [
+						{ "target_name": "Windows", "link": "InternalMod/1.0.0/Windows.smod", "hash": "abc" },
+						{ "target_name": "LinuxServer", "link": "https://example.com/LinuxServer.smod", "hash": "def" }
+					],
+					"required_on_remote": true
+				}
+			]
+		}
+	}
+}`
+
+type stubProvider struct {
+	resolver.MockProvider
+	LocalProvider
+}
+
+func (p stubProvider) ModVersionsWithDependencies(ctx context.Context, modID string) ([]resolver.ModVersion, error) {
+	return p.MockProvider.ModVersionsWithDependencies(ctx, modID) //nolint:wrapcheck
+}
+
+func (p stubProvider) GetModName(ctx context.Context, modReference string) (*resolver.ModName, error) {
+	return p.MockProvider.GetModName(ctx, modReference) //nolint:wrapcheck
+}
+
+func TestStaticProviderDirectory(t *testing.T) {
+	testza.AssertNoError(t, localregistry.Init())
+
+	dir := t.TempDir()
+	p := NewStaticProvider(dir)
+
+	// Failing to load the index is not kept
+	_, err := p.ModVersionsWithDependencies(context.Background(), "InternalMod")
+	testza.AssertNotNil(t, err)
+	testza.AssertFalse(t, errors.Is(err, ErrModNotFound))
+
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(dir, StaticIndexFile), []byte(testIndex), 0o600))
+
+	versions, err := p.ModVersionsWithDependencies(context.Background(), "InternalMod")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, versions, 1)
+	testza.AssertEqual(t, "file://"+filepath.Join(dir, "InternalMod", "1.0.0", "Windows.smod"), versions[0].Targets[0].Link)
+	testza.AssertEqual(t, "https://example.com/LinuxServer.smod", versions[0].Targets[1].Link)
+
+	name, err := p.GetModName(context.Background(), "InternalMod")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "Internal Mod", name.Name)
+
+	_, err = p.ModVersionsWithDependencies(context.Background(), "SML")
+	testza.AssertTrue(t, errors.Is(err, ErrModNotFound))
+
+	// Count is the number of matches on all pages
+	mods, err := p.Mods(context.Background(), ficsit.ModFilter{Offset: 1})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 1, mods.Mods.Count)
+	testza.AssertLen(t, mods.Mods.Mods, 0)
+}
+
+func TestStaticProviderHTTP(t *testing.T) {
+	testza.AssertNoError(t, localregistry.Init())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo/"+StaticIndexFile {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testIndex))
+	}))
+	defer server.Close()
+
+	p := NewStaticProvider(server.URL + "/repo/")
+
+	versions, err := p.ModVersionsWithDependencies(context.Background(), "InternalMod")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, server.URL+"/repo/InternalMod/1.0.0/Windows.smod", versions[0].Targets[0].Link)
+}
+
+func TestRepositoryPriority(t *testing.T) {
+	testza.AssertNoError(t, localregistry.Init())
+
+	dir := t.TempDir()
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(dir, StaticIndexFile), []byte(testIndex), 0o600))
+
+	p := InitRepositoryProvider([]string{dir}, stubProvider{})
+
+	versions, err := p.ModVersionsWithDependencies(context.Background(), "InternalMod")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "1.0.0", versions[0].Version)
+
+	// Mods missing from the static repository fall through to SMR
+	versions, err = p.ModVersionsWithDependencies(context.Background(), "SML")
+	testza.AssertNoError(t, err)
+	testza.AssertNotEqual(t, 0, len(versions))
+
+	// Repositories which cannot be read do not fall through
+	p = InitRepositoryProvider([]string{t.TempDir()}, stubProvider{})
+
+	_, err = p.ModVersionsWithDependencies(context.Background(), "SML")
+	testza.AssertNotNil(t, err)
+
+	_, err = p.Mods(context.Background(), ficsit.ModFilter{})
+	testza.AssertNotNil(t, err)
+}
@@ -1,17 +1,17 @@
 package cli
 
 import (
+	"context"
 	"log/slog"
 	"math"
-	"os"
-	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/MarvinJWendt/testza"
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
 	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit/ficsittest"
 )
 
 func init() {
@@ -35,115 +35,93 @@ func installWatcher() chan<- InstallUpdate {
 }
 
 func TestClientOnlyMod(t *testing.T) {
-	ctx, err := InitCLI(false)
-	testza.AssertNoError(t, err)
+	ctx, installation, d := newMemoryInstallation(t, "ClientOnlyModTest", ficsittest.Mod{
+		Reference: "ClientOnlyMod",
+		Versions: []ficsittest.Version{
+			{Version: "1.0.0", Targets: []string{"Windows"}},
+		},
+	})
+
+	profile := ctx.Profiles.GetProfile("ClientOnlyModTest")
+	profile.RequiredTargets = []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameWindowsServer, resolver.TargetNameLinuxServer}
+	testza.AssertNoError(t, profile.AddMod("ClientOnlyMod", "1.0.0"))
 
-	err = ctx.Wipe()
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
 	testza.AssertNoError(t, err)
 
-	ctx.Provider = MockProvider{}
-
-	profileName := "ClientOnlyModTest"
-	profile, err := ctx.Profiles.AddProfile(profileName)
-	profile.RequiredTargets = []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameWindowsServer, resolver.TargetNameLinuxServer}
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/ClientOnlyMod")
 	testza.AssertNoError(t, err)
-	testza.AssertNoError(t, profile.AddMod("ClientOnlyMod", "<=0.0.1"))
-
-	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
-	if serverLocation != "" {
-		time.Sleep(time.Second)
-		testza.AssertNoError(t, os.RemoveAll(filepath.Join(serverLocation, "FactoryGame", "Mods")))
-		time.Sleep(time.Second)
-
-		installation, err := ctx.Installations.AddInstallation(ctx, serverLocation, profileName)
-		testza.AssertNoError(t, err)
-		testza.AssertNotNil(t, installation)
-
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
-	}
+	testza.AssertFalse(t, exists)
 }
 
 func TestServerOnlyMod(t *testing.T) {
-	ctx, err := InitCLI(false)
-	testza.AssertNoError(t, err)
+	ctx, installation, d := newMemoryInstallation(t, "ServerOnlyModTest", ficsittest.Mod{
+		Reference: "ServerOnlyMod",
+		Versions: []ficsittest.Version{
+			{Version: "1.0.0", Targets: []string{"WindowsServer", "LinuxServer"}},
+		},
+	})
+
+	profile := ctx.Profiles.GetProfile("ServerOnlyModTest")
+	profile.RequiredTargets = []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameWindowsServer, resolver.TargetNameLinuxServer}
+	testza.AssertNoError(t, profile.AddMod("ServerOnlyMod", "1.0.0"))
 
-	err = ctx.Wipe()
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
 	testza.AssertNoError(t, err)
 
-	ctx.Provider = MockProvider{}
-
-	profileName := "ServerOnlyModTest"
-	profile, err := ctx.Profiles.AddProfile(profileName)
-	profile.RequiredTargets = []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameWindowsServer, resolver.TargetNameLinuxServer}
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/ServerOnlyMod/.smm")
 	testza.AssertNoError(t, err)
-	testza.AssertNoError(t, profile.AddMod("ServerOnlyMod", "<=0.0.1"))
-
-	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
-	if serverLocation != "" {
-		time.Sleep(time.Second)
-		testza.AssertNoError(t, os.RemoveAll(filepath.Join(serverLocation, "FactoryGame", "Mods")))
-		time.Sleep(time.Second)
-
-		installation, err := ctx.Installations.AddInstallation(ctx, serverLocation, profileName)
-		testza.AssertNoError(t, err)
-		testza.AssertNotNil(t, installation)
-
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
-	}
+	testza.AssertTrue(t, exists)
 }
 
 func TestRemoveWhenNotSupported(t *testing.T) {
-	ctx, err := InitCLI(false)
-	testza.AssertNoError(t, err)
-
-	err = ctx.Wipe()
-	testza.AssertNoError(t, err)
-
-	ctx.Provider = MockProvider{}
-
-	profileName := "ClientOnlyModTest"
-	profile, err := ctx.Profiles.AddProfile(profileName)
+	ctx, installation, d := newMemoryInstallation(t, "ClientOnlyModTest", ficsittest.Mod{
+		Reference: "LaterClientOnlyMod",
+		Versions: []ficsittest.Version{
+			{Version: "0.0.1", Targets: []string{"Windows", "WindowsServer", "LinuxServer"}},
+			{Version: "0.0.2", Targets: []string{"Windows"}},
+		},
+	})
+
+	profile := ctx.Profiles.GetProfile("ClientOnlyModTest")
 	profile.RequiredTargets = []resolver.TargetName{resolver.TargetNameWindows, resolver.TargetNameWindowsServer, resolver.TargetNameLinuxServer}
-	testza.AssertNoError(t, err)
 	testza.AssertNoError(t, profile.AddMod("LaterClientOnlyMod", "0.0.1"))
 
-	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
-	if serverLocation != "" {
-		time.Sleep(time.Second)
-		testza.AssertNoError(t, os.RemoveAll(filepath.Join(serverLocation, "FactoryGame", "Mods")))
-		time.Sleep(time.Second)
-
-		installation, err := ctx.Installations.AddInstallation(ctx, serverLocation, profileName)
-		testza.AssertNoError(t, err)
-		testza.AssertNotNil(t, installation)
-
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
-
-		_, err = os.Stat(filepath.Join(serverLocation, "FactoryGame", "Mods", "LaterClientOnlyMod"))
-		testza.AssertNoError(t, err)
-
-		testza.AssertNoError(t, profile.AddMod("LaterClientOnlyMod", "0.0.2"))
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
 
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/LaterClientOnlyMod")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
 
-		_, err = os.Stat(filepath.Join(serverLocation, "FactoryGame", "Mods", "LaterClientOnlyMod"))
-		testza.AssertNotNil(t, err)
-		testza.AssertErrorIs(t, err, os.ErrNotExist)
-	}
-}
+	testza.AssertNoError(t, profile.AddMod("LaterClientOnlyMod", "0.0.2"))
 
-func TestUpdateMods(t *testing.T) {
-	ctx, err := InitCLI(false)
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
 	testza.AssertNoError(t, err)
 
-	err = ctx.Wipe()
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/LaterClientOnlyMod")
 	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+}
 
-	ctx.Provider = MockProvider{}
+func TestUpdateMods(t *testing.T) {
+	ctx, installation, _ := newMemoryInstallation(t, "UpdateTest", ficsittest.Mod{
+		Reference: "FicsitRemoteMonitoring",
+		Versions: []ficsittest.Version{
+			{
+				Version:          "0.9.8",
+				Dependencies:     []ficsit.Dependency{{ModID: "SML", Condition: "^3.6.0"}},
+				Targets:          []string{"Windows", "WindowsServer", "LinuxServer"},
+				RequiredOnRemote: true,
+			},
+			{
+				Version:          "0.10.0",
+				Dependencies:     []ficsit.Dependency{{ModID: "SML", Condition: "^3.6.0"}},
+				Targets:          []string{"Windows", "WindowsServer", "LinuxServer"},
+				RequiredOnRemote: true,
+			},
+		},
+	})
 
 	depResolver := resolver.NewDependencyResolver(ctx.Provider)
 
@@ -155,39 +133,31 @@ func TestUpdateMods(t *testing.T) {
 	testza.AssertNotNil(t, oldLockfile)
 	testza.AssertLen(t, oldLockfile.Mods, 2)
 
-	profileName := "UpdateTest"
-	profile, err := ctx.Profiles.AddProfile(profileName)
-	testza.AssertNoError(t, err)
+	profile := ctx.Profiles.GetProfile("UpdateTest")
+	profile.RemoveMod("AreaActions")
 	testza.AssertNoError(t, profile.AddMod("FicsitRemoteMonitoring", "<=0.10.0"))
 
-	serverLocation := os.Getenv("SF_DEDICATED_SERVER")
-	if serverLocation != "" {
-		installation, err := ctx.Installations.AddInstallation(ctx, serverLocation, profileName)
-		testza.AssertNoError(t, err)
-		testza.AssertNotNil(t, installation)
-
-		err = installation.WriteLockFile(ctx, oldLockfile)
-		testza.AssertNoError(t, err)
+	err = installation.WriteLockFile(context.Background(), ctx, oldLockfile)
+	testza.AssertNoError(t, err)
 
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
 
-		lockFile, err := installation.LockFile(ctx)
-		testza.AssertNoError(t, err)
+	lockFile, err := installation.LockFile(context.Background(), ctx)
+	testza.AssertNoError(t, err)
 
-		testza.AssertEqual(t, 2, len(lockFile.Mods))
-		testza.AssertEqual(t, "0.9.8", (lockFile.Mods)["FicsitRemoteMonitoring"].Version)
+	testza.AssertEqual(t, 2, len(lockFile.Mods))
+	testza.AssertEqual(t, "0.9.8", (lockFile.Mods)["FicsitRemoteMonitoring"].Version)
 
-		err = installation.UpdateMods(ctx, []string{"FicsitRemoteMonitoring"})
-		testza.AssertNoError(t, err)
+	err = installation.UpdateMods(context.Background(), ctx, []string{"FicsitRemoteMonitoring"})
+	testza.AssertNoError(t, err)
 
-		lockFile, err = installation.LockFile(ctx)
-		testza.AssertNoError(t, err)
+	lockFile, err = installation.LockFile(context.Background(), ctx)
+	testza.AssertNoError(t, err)
 
-		testza.AssertEqual(t, 2, len(lockFile.Mods))
-		testza.AssertEqual(t, "0.10.0", (lockFile.Mods)["FicsitRemoteMonitoring"].Version)
+	testza.AssertEqual(t, 2, len(lockFile.Mods))
+	testza.AssertEqual(t, "0.10.0", (lockFile.Mods)["FicsitRemoteMonitoring"].Version)
 
-		err = installation.Install(ctx, installWatcher())
-		testza.AssertNoError(t, err)
-	}
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
 }
@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// ErrGameRunning is returned when an installation is applied while the game or server is running
+var ErrGameRunning = errors.New("the game is running")
+
+// runningPollInterval is how often a running installation is checked while waiting for it to stop
+var runningPollInterval = 2 * time.Second
+
+// processNames are the prefixes of the process names of the game and servers of each target
+var processNames = map[string][]string{
+	"Windows":       {"FactoryGame"},
+	"WindowsServer": {"FactoryServer", "UnrealServer"},
+	"LinuxServer":   {"FactoryServer", "UnrealServer"},
+}
+
+type InstallOptions struct {
+	// Force applies even if the game or server is running
+	Force bool
+	// OverwriteConfigs deploys the configs of the profile even if they were edited on the installation
+	OverwriteConfigs bool
+	// Wait is how long to wait for the game or server to stop before refusing to apply.
+	// Waits until the context is cancelled if negative.
+	Wait time.Duration
+
+	// lockfile is installed instead of resolving the profile, when rolling back
+	lockfile   *resolver.LockFile
+	rollbackOf int
+	// profile is the profile recorded with the entry rolled back to, its configs are deployed instead of the current ones
+	profile *Profile
+}
+
+type process struct {
+	Name string
+	Path string
+	PID  int
+}
+
+// Running checks if the game or server of the installation is running, and returns what is running.
+// If the installation has a running probe, the probe decides, otherwise local installations are checked for
+// running processes, and remote installations are assumed to be stopped.
+func (i *Installation) Running(ctx context.Context, global *GlobalContext, platform *Platform) (string, error) {
+	if i.RunningProbe != "" {
+		return i.runProbe(ctx)
+	}
+
+	parsed, err := url.Parse(i.Path)
+	if err == nil && isRemoteScheme(parsed.Scheme) {
+		return "", nil
+	}
+
+	processes, err := localProcesses(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	basePath, err := filepath.EvalSymlinks(i.BasePath())
+	if err != nil {
+		basePath = i.BasePath()
+	}
+
+	var byName []process
+	for _, p := range processes {
+		if runsFrom(p, basePath) {
+			return fmt.Sprintf("%s (pid %d)", p.Name, p.PID), nil
+		}
+
+		if matchesByName(p, platform) {
+			byName = append(byName, p)
+		}
+	}
+
+	if len(byName) == 0 {
+		return "", nil
+	}
+
+	running := fmt.Sprintf("%s (pid %d)", byName[0].Name, byName[0].PID)
+
+	// A process matched by name could belong to any local installation of the same platform,
+	// it is assumed to be running from this one as well
+	others := i.otherLocalInstallations(ctx, global, platform)
+	if len(others) > 0 {
+		paths := []string{i.Path}
+		for _, other := range others {
+			paths = append(paths, other.Path)
+		}
+		return fmt.Sprintf("%s, started from one of %s", running, strings.Join(paths, ", ")), nil
+	}
+
+	return running, nil
+}
+
+// otherLocalInstallations returns the other local installations of the same platform
+func (i *Installation) otherLocalInstallations(ctx context.Context, global *GlobalContext, platform *Platform) []*Installation {
+	var others []*Installation
+	for _, installation := range global.Installations.Installations {
+		if filepath.Clean(installation.Path) == filepath.Clean(i.Path) {
+			continue
+		}
+
+		parsed, err := url.Parse(installation.Path)
+		if err == nil && isRemoteScheme(parsed.Scheme) {
+			continue
+		}
+
+		otherPlatform, err := installation.GetPlatform(ctx, global)
+		if err != nil || otherPlatform.TargetName != platform.TargetName {
+			continue
+		}
+
+		others = append(others, installation)
+	}
+
+	return others
+}
+
+// runProbe runs the running probe on the machine of the installation if its disk can run commands, or locally otherwise.
+// The game is running if the probe exits successfully.
+func (i *Installation) runProbe(ctx context.Context) (string, error) {
+	d, err := i.GetDisk()
+	if err != nil {
+		return "", err
+	}
+
+	var output []byte
+	if runner, ok := d.(disk.Runner); ok {
+		output, err = runner.Run(ctx, i.RunningProbe, nil)
+	} else {
+		output, err = runLocalCommand(ctx, i.RunningProbe, nil)
+	}
+
+	if err != nil {
+		var exitErr *disk.ExitError
+		if errors.As(err, &exitErr) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to run running probe: %w", err)
+	}
+
+	if running := strings.TrimSpace(string(output)); running != "" {
+		return running, nil
+	}
+
+	return "running probe succeeded", nil
+}
+
+// runsFrom checks if the executable of the process is in the installation
+func runsFrom(p process, basePath string) bool {
+	if p.Path == "" {
+		return false
+	}
+
+	relative, err := filepath.Rel(basePath, p.Path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// matchesByName checks if the process could be the game or server of the platform without knowing where it runs from.
+// Processes of which the executable is not known, or which run through Wine or Proton, are matched by name.
+func matchesByName(p process, platform *Platform) bool {
+	if p.Path != "" && (platform.TargetName != "Windows" || runtime.GOOS == "windows") {
+		return false
+	}
+
+	for _, name := range processNames[platform.TargetName] {
+		if strings.HasPrefix(strings.ToLower(p.Name), strings.ToLower(name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRunning refuses to apply while the game or server is running, unless forced.
+// Sends a waiting update while waiting for it to stop.
+func (i *Installation) checkRunning(ctx context.Context, global *GlobalContext, platform *Platform, options InstallOptions, updates chan<- InstallUpdate) error {
+	if options.Force {
+		return nil
+	}
+
+	var deadline <-chan time.Time
+	if options.Wait >= 0 {
+		deadline = time.After(options.Wait)
+	}
+
+	for {
+		running, err := i.Running(ctx, global, platform)
+		if err != nil {
+			return fmt.Errorf("failed to check if the game is running: %w", err)
+		}
+
+		if running == "" {
+			return nil
+		}
+
+		if options.Wait == 0 {
+			return fmt.Errorf("%w: %s, stop it or apply with force", ErrGameRunning, running)
+		}
+
+		slog.Info("waiting for the game to stop", slog.String("path", i.Path), slog.String("running", running))
+
+		if updates != nil {
+			updates <- InstallUpdate{
+				Type:    InstallUpdateTypeWaiting,
+				Message: running,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-deadline:
+			return fmt.Errorf("%w: %s is still running after %s", ErrGameRunning, running, options.Wait)
+		case <-time.After(runningPollInterval):
+		}
+	}
+}
+
+// localProcesses lists the processes running on this machine
+func localProcesses(ctx context.Context) ([]process, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return linuxProcesses()
+	case "windows":
+		return windowsProcesses(ctx)
+	default:
+		return psProcesses(ctx)
+	}
+}
+
+func linuxProcesses() ([]process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var processes []process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		// Processes of other users can not be inspected, and processes may exit while listing
+		comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+
+		exe, _ := os.Readlink(filepath.Join("/proc", entry.Name(), "exe"))
+
+		processes = append(processes, process{
+			PID:  pid,
+			Name: strings.TrimSpace(string(comm)),
+			Path: exe,
+		})
+	}
+
+	return processes, nil
+}
+
+// windowsProcesses lists the processes with their executables using PowerShell,
+// or only with their names using tasklist if PowerShell is not available.
+// The executables of processes of other users are not known.
+func windowsProcesses(ctx context.Context) ([]process, error) {
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"Get-Process | Select-Object Id,ProcessName,Path | ConvertTo-Csv -NoTypeInformation").Output()
+	if err != nil {
+		slog.Debug("failed to list processes with powershell", slog.Any("err", err))
+		return tasklistProcesses(ctx)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse process list: %w", err)
+	}
+
+	processes := make([]process, 0, len(records))
+	for _, record := range records {
+		if len(record) < 3 {
+			continue
+		}
+
+		// The header is skipped as well
+		pid, err := strconv.Atoi(record[0])
+		if err != nil {
+			continue
+		}
+
+		processes = append(processes, process{
+			PID:  pid,
+			Name: record[1],
+			Path: record[2],
+		})
+	}
+
+	return processes, nil
+}
+
+func tasklistProcesses(ctx context.Context) ([]process, error) {
+	output, err := exec.CommandContext(ctx, "tasklist", "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse process list: %w", err)
+	}
+
+	processes := make([]process, 0, len(records))
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(record[1])
+		if err != nil {
+			continue
+		}
+
+		processes = append(processes, process{
+			PID:  pid,
+			Name: record[0],
+		})
+	}
+
+	return processes, nil
+}
+
+func psProcesses(ctx context.Context) ([]process, error) {
+	output, err := exec.CommandContext(ctx, "ps", "-axo", "pid=,comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var processes []process
+	for _, line := range strings.Split(string(output), "\n") {
+		pidString, command, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+
+		pid, err := strconv.Atoi(pidString)
+		if err != nil {
+			continue
+		}
+
+		command = strings.TrimSpace(command)
+
+		p := process{
+			PID:  pid,
+			Name: filepath.Base(command),
+		}
+
+		if filepath.IsAbs(command) {
+			p.Path = command
+		}
+
+		processes = append(processes, p)
+	}
+
+	return processes, nil
+}
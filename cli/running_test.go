@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestRunningProbe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// The probe uses a POSIX shell
+		return
+	}
+
+	defer func(interval time.Duration) {
+		runningPollInterval = interval
+	}(runningPollInterval)
+	runningPollInterval = 10 * time.Millisecond
+
+	ctx, installation, d := newMemoryInstallation(t, "RunningTest")
+
+	running := filepath.Join(t.TempDir(), "running")
+	testza.AssertNoError(t, os.WriteFile(running, []byte{}, 0o644))
+	installation.RunningProbe = "test -f " + running
+
+	err := installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertTrue(t, errors.Is(err, ErrGameRunning))
+
+	exists, err := d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{Wait: 50 * time.Millisecond}, installWatcher())
+	testza.AssertTrue(t, errors.Is(err, ErrGameRunning))
+
+	// The installation continues once the game stops
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.Remove(running)
+	}()
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{Wait: -1}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, exists)
+
+	testza.AssertNoError(t, os.WriteFile(running, []byte{}, 0o644))
+	installation.Vanilla = true
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{Force: true}, installWatcher())
+	testza.AssertNoError(t, err)
+
+	exists, err = d.Exists(context.Background(), "/server/FactoryGame/Mods/AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, exists)
+}
+
+func TestRunningProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	testza.AssertNoError(t, err)
+
+	writeFakeInstallation(t, dir, "FactoryServer.sh", platforms[3])
+
+	installation := &Installation{Path: dir}
+	global := &GlobalContext{Installations: &Installations{}, Profiles: &Profiles{}}
+
+	running, err := installation.Running(context.Background(), global, &platforms[3])
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", running)
+
+	// A copy of sleep pretends to be the server
+	data, err := os.ReadFile(sleep)
+	testza.AssertNoError(t, err)
+
+	server := filepath.Join(dir, "Engine", "Binaries", "Linux", "FactoryServer-Linux-Shipping")
+	testza.AssertNoError(t, os.WriteFile(server, data, 0o755))
+
+	cmd := exec.Command(server, "30")
+	testza.AssertNoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	running, err = installation.Running(context.Background(), global, &platforms[3])
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, strings.HasSuffix(running, "(pid "+strconv.Itoa(cmd.Process.Pid)+")"))
+
+	// Another installation is not affected
+	other := &Installation{Path: t.TempDir()}
+	running, err = other.Running(context.Background(), global, &platforms[3])
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "", running)
+}
+
+func TestRunningProcessByName(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	global := &GlobalContext{
+		Installations: &Installations{},
+		Profiles:      &Profiles{Profiles: map[string]*Profile{"Default": {Name: "Default"}}},
+	}
+
+	game := t.TempDir()
+	writeFakeInstallation(t, game, "FactoryGameSteam.exe", platforms[5])
+	installation := &Installation{Path: game, Profile: "Default"}
+	global.Installations.Installations = append(global.Installations.Installations, installation)
+
+	// A copy of sleep pretends to be the game running through Proton, which is only known by its name
+	data, err := os.ReadFile(sleep)
+	testza.AssertNoError(t, err)
+
+	proton := filepath.Join(t.TempDir(), "FactoryGameSteam-Win64-Shipping.exe")
+	testza.AssertNoError(t, os.WriteFile(proton, data, 0o755))
+
+	cmd := exec.Command(proton, "30")
+	testza.AssertNoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	// The only local installation of the game is running
+	running, err := installation.Running(context.Background(), global, &platforms[5])
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, strings.HasSuffix(running, "(pid "+strconv.Itoa(cmd.Process.Pid)+")"))
+
+	// With another local installation of the game, it is not known which one is running,
+	// so both are considered running
+	other := t.TempDir()
+	writeFakeInstallation(t, other, "FactoryGameSteam.exe", platforms[5])
+	otherInstallation := &Installation{Path: other, Profile: "Default"}
+	global.Installations.Installations = append(global.Installations.Installations, otherInstallation)
+
+	running, err = installation.Running(context.Background(), global, &platforms[5])
+	testza.AssertNoError(t, err)
+	testza.AssertContains(t, running, "(pid "+strconv.Itoa(cmd.Process.Pid)+")")
+	testza.AssertContains(t, running, game)
+	testza.AssertContains(t, running, other)
+
+	err = otherInstallation.checkRunning(context.Background(), global, &platforms[5], InstallOptions{}, nil)
+	testza.AssertTrue(t, errors.Is(err, ErrGameRunning))
+	testza.AssertContains(t, err.Error(), game)
+	testza.AssertContains(t, err.Error(), other)
+
+	testza.AssertNoError(t, otherInstallation.checkRunning(context.Background(), global, &platforms[5], InstallOptions{Force: true}, nil))
+
+	// Installations of other platforms do not matter
+	server := t.TempDir()
+	writeFakeInstallation(t, server, "FactoryServer.sh", platforms[3])
+	global.Installations.Installations = []*Installation{installation, {Path: server, Profile: "Default"}}
+
+	running, err = installation.Running(context.Background(), global, &platforms[5])
+	testza.AssertNoError(t, err)
+	testza.AssertNotEqual(t, "", running)
+}
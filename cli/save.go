@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+	"unicode/utf16"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+)
+
+// Save header versions which added the fields read from the header
+const (
+	saveHeaderSessionName    = 2
+	saveHeaderPlayDuration   = 3
+	saveHeaderSaveTime       = 4
+	saveHeaderVisibility     = 5
+	saveHeaderEditorVersion  = 7
+	saveHeaderModMetadata    = 8
+	saveHeaderSaveName       = 14
+	maxSaveHeaderStringBytes = 16 * 1024 * 1024
+)
+
+// ticksToUnixEpoch is the amount of Unreal Engine date ticks (100ns since 0001-01-01) before the unix epoch
+const ticksToUnixEpoch = 621355968000000000
+
+// SaveHeader is the uncompressed header at the start of a Satisfactory save file
+type SaveHeader struct {
+	SaveTime     time.Time
+	SaveName     string
+	MapName      string
+	MapOptions   string
+	SessionName  string
+	Mods         []*SaveMod
+	PlayDuration time.Duration
+	Version      int32
+	SaveVersion  int32
+	BuildVersion int32
+	// Modded is set by SML on saves which were made with mods loaded
+	Modded bool
+}
+
+// SaveMod is a mod listed in the mod metadata written by SML into save headers
+type SaveMod struct {
+	Reference string `json:"Reference"`
+	Name      string `json:"Name"`
+	Version   string `json:"Version"`
+	// Reason explains why the mod was not added to the profile
+	Reason string `json:"-"`
+	// Added is true if the mod was found in the repository and added to the profile
+	Added bool `json:"-"`
+}
+
+type saveModMetadata struct {
+	Mods    []*SaveMod `json:"Mods"`
+	Version int        `json:"Version"`
+}
+
+// ReadSaveHeader reads the header of a save file, including the mods the save was made with
+func ReadSaveHeader(reader io.Reader) (*SaveHeader, error) {
+	r := &saveReader{reader: bufio.NewReader(reader)}
+
+	header := &SaveHeader{
+		Version:      r.int32(),
+		SaveVersion:  r.int32(),
+		BuildVersion: r.int32(),
+	}
+
+	if r.err == nil && (header.Version < 0 || header.Version > 255) {
+		return nil, fmt.Errorf("not a save file, unknown header version %d", header.Version)
+	}
+
+	if header.Version >= saveHeaderSaveName {
+		header.SaveName = r.string()
+	}
+
+	header.MapName = r.string()
+	header.MapOptions = r.string()
+
+	if header.Version >= saveHeaderSessionName {
+		header.SessionName = r.string()
+	}
+
+	if header.Version >= saveHeaderPlayDuration {
+		header.PlayDuration = time.Duration(r.int32()) * time.Second
+	}
+
+	if header.Version >= saveHeaderSaveTime {
+		ticks := r.int64()
+		header.SaveTime = time.Unix((ticks-ticksToUnixEpoch)/10_000_000, (ticks%10_000_000)*100).UTC()
+	}
+
+	if header.Version >= saveHeaderVisibility {
+		r.skip(1)
+	}
+
+	if header.Version >= saveHeaderEditorVersion {
+		r.skip(4)
+	}
+
+	var metadata string
+	if header.Version >= saveHeaderModMetadata {
+		metadata = r.string()
+		header.Modded = r.int32() != 0
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to read save header: %w", r.err)
+	}
+
+	if header.Version < saveHeaderModMetadata {
+		return nil, fmt.Errorf("save header version %d is too old to contain mod metadata", header.Version)
+	}
+
+	if metadata == "" {
+		return header, nil
+	}
+
+	var modMetadata saveModMetadata
+	if err := json.Unmarshal([]byte(metadata), &modMetadata); err != nil {
+		return nil, fmt.Errorf("failed to parse save mod metadata: %w", err)
+	}
+
+	header.Mods = modMetadata.Mods
+
+	return header, nil
+}
+
+// ReadSaveHeaderFile reads the header of the save file at the path
+func ReadSaveHeaderFile(savePath string) (*SaveHeader, error) {
+	f, err := os.Open(savePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open save file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadSaveHeader(f)
+}
+
+// AddProfileFromSave creates a profile with the mods the save was made with.
+// Mods are added with a caret constraint on their saved version, which is what SML requires by default when joining.
+// Mods which are not in the repository are not added, and returned with a reason.
+func (p *Profiles) AddProfileFromSave(ctx context.Context, global *GlobalContext, header *SaveHeader, name string) (*Profile, []*SaveMod, error) {
+	if !header.Modded && len(header.Mods) == 0 {
+		return nil, nil, errors.New("save was not made with mods")
+	}
+
+	profile, err := p.AddProfile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mods := make([]*SaveMod, 0, len(header.Mods))
+	for _, mod := range header.Mods {
+		if mod == nil {
+			continue
+		}
+
+		mods = append(mods, mod)
+
+		if mod.Reference == "" {
+			mod.Reason = "no mod reference"
+			continue
+		}
+
+		if !utils.SemVerRegex.MatchString(mod.Version) {
+			mod.Reason = "invalid version " + mod.Version
+			continue
+		}
+
+		versions, err := global.Provider.ModVersionsWithDependencies(ctx, mod.Reference)
+		if err != nil && !errors.Is(err, provider.ErrModNotFound) {
+			_ = p.DeleteProfile(name)
+			return nil, nil, fmt.Errorf("failed to fetch mod %s: %w", mod.Reference, err)
+		}
+
+		if len(versions) == 0 {
+			slog.Info("mod not found in the repository", slog.String("mod_reference", mod.Reference), slog.Any("err", err))
+			mod.Reason = "not found in the repository"
+			continue
+		}
+
+		if err := profile.AddMod(mod.Reference, "^"+mod.Version); err != nil {
+			_ = p.DeleteProfile(name)
+			return nil, nil, fmt.Errorf("failed to add %s: %w", mod.Reference, err)
+		}
+
+		mod.Added = true
+	}
+
+	return profile, mods, nil
+}
+
+// saveReader reads little-endian Unreal Engine values, keeping the first error
+type saveReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *saveReader) read(data any) {
+	if r.err != nil {
+		return
+	}
+
+	r.err = binary.Read(r.reader, binary.LittleEndian, data)
+}
+
+func (r *saveReader) int32() int32 {
+	var value int32
+	r.read(&value)
+	return value
+}
+
+func (r *saveReader) int64() int64 {
+	var value int64
+	r.read(&value)
+	return value
+}
+
+func (r *saveReader) skip(n int64) {
+	if r.err != nil {
+		return
+	}
+
+	_, r.err = io.CopyN(io.Discard, r.reader, n)
+}
+
+// string reads an FString: a length including the null terminator, negative for UTF-16 strings
+func (r *saveReader) string() string {
+	length := int64(r.int32())
+	if r.err != nil || length == 0 {
+		return ""
+	}
+
+	if length < 0 {
+		length = -length
+		if length*2 > maxSaveHeaderStringBytes {
+			r.err = fmt.Errorf("string of %d characters is too long", length)
+			return ""
+		}
+
+		chars := make([]uint16, length)
+		r.read(chars)
+		if r.err != nil {
+			return ""
+		}
+
+		return string(utf16.Decode(chars[:length-1]))
+	}
+
+	if length > maxSaveHeaderStringBytes {
+		r.err = fmt.Errorf("string of %d bytes is too long", length)
+		return ""
+	}
+
+	data := make([]byte, length)
+	r.read(data)
+	if r.err != nil {
+		return ""
+	}
+
+	// Strings without characters above Latin-1 are written one byte per character
+	runes := make([]rune, length-1)
+	for i, b := range data[:length-1] {
+		runes[i] = rune(b)
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+// saveHeaderFixture writes a save header as the game does, with the fields up to the mod metadata
+func saveHeaderFixture(version int32, sessionName string, modMetadata string, modded bool) []byte {
+	var buf bytes.Buffer
+
+	writeString := func(value string) {
+		if value == "" {
+			_ = binary.Write(&buf, binary.LittleEndian, int32(0))
+			return
+		}
+
+		for _, c := range value {
+			if c > 0xFF {
+				chars := append(utf16.Encode([]rune(value)), 0)
+				_ = binary.Write(&buf, binary.LittleEndian, -int32(len(chars)))
+				_ = binary.Write(&buf, binary.LittleEndian, chars)
+				return
+			}
+		}
+
+		_ = binary.Write(&buf, binary.LittleEndian, int32(len(value)+1))
+		buf.WriteString(value)
+		buf.WriteByte(0)
+	}
+
+	_ = binary.Write(&buf, binary.LittleEndian, []int32{version, 46, 365306})
+	if version >= saveHeaderSaveName {
+		writeString("Save")
+	}
+	writeString("Persistent_Level")
+	writeString("?startloc=Grass Fields")
+	writeString(sessionName)
+	_ = binary.Write(&buf, binary.LittleEndian, int32(3600))
+	_ = binary.Write(&buf, binary.LittleEndian, int64(ticksToUnixEpoch+17_000_000_000_000_000))
+	buf.WriteByte(1)
+	_ = binary.Write(&buf, binary.LittleEndian, int32(42))
+	writeString(modMetadata)
+
+	var isModded int32
+	if modded {
+		isModded = 1
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, isModded)
+
+	// Start of the compressed body
+	buf.Write([]byte{0xC1, 0x83, 0x2A, 0x9E})
+
+	return buf.Bytes()
+}
+
+const saveModMetadataFixture = `{"Version":1,"FullMapName":"/Game/FactoryGame/Map/GameLevel01/Persistent_Level","Mods":[` +
+	`{"Reference":"SML","Name":"Satisfactory Mod Loader","Version":"3.6.0"},` +
+	`{"Reference":"AreaActions","Name":"Area Actions","Version":"1.0.0"},` +
+	`{"Reference":"PrivateMod","Name":"Private Mod","Version":"0.1.0"}]}`
+
+func TestReadSaveHeader(t *testing.T) {
+	for _, version := range []int32{13, 14} {
+		header, err := ReadSaveHeader(bytes.NewReader(saveHeaderFixture(version, "Fabrik ✓", saveModMetadataFixture, true)))
+		testza.AssertNoError(t, err)
+		testza.AssertEqual(t, version, header.Version)
+		testza.AssertEqual(t, "Persistent_Level", header.MapName)
+		testza.AssertEqual(t, "?startloc=Grass Fields", header.MapOptions)
+		testza.AssertEqual(t, "Fabrik ✓", header.SessionName)
+		testza.AssertEqual(t, time.Hour, header.PlayDuration)
+		testza.AssertEqual(t, time.Unix(1_700_000_000, 0).UTC(), header.SaveTime)
+		testza.AssertTrue(t, header.Modded)
+		testza.AssertLen(t, header.Mods, 3)
+		testza.AssertEqual(t, "AreaActions", header.Mods[1].Reference)
+		testza.AssertEqual(t, "1.0.0", header.Mods[1].Version)
+	}
+
+	header, err := ReadSaveHeader(bytes.NewReader(saveHeaderFixture(13, "Vanilla", "", false)))
+	testza.AssertNoError(t, err)
+	testza.AssertFalse(t, header.Modded)
+	testza.AssertLen(t, header.Mods, 0)
+
+	fixture := saveHeaderFixture(13, "Truncated", saveModMetadataFixture, true)
+	_, err = ReadSaveHeader(bytes.NewReader(fixture[:len(fixture)-20]))
+	testza.AssertNotNil(t, err)
+
+	_, err = ReadSaveHeader(bytes.NewReader([]byte("PK\x03\x04 not a save file")))
+	testza.AssertNotNil(t, err)
+}
+
+func TestAddProfileFromSave(t *testing.T) {
+	ctx, installation, _ := newMemoryInstallation(t, "SaveTest")
+
+	header, err := ReadSaveHeader(bytes.NewReader(saveHeaderFixture(13, "Session", saveModMetadataFixture, true)))
+	testza.AssertNoError(t, err)
+
+	profile, mods, err := ctx.Profiles.AddProfileFromSave(context.Background(), ctx, header, "FromSave")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, mods, 3)
+	testza.AssertEqual(t, "^3.6.0", profile.Mods["SML"].Version)
+	testza.AssertEqual(t, "^1.0.0", profile.Mods["AreaActions"].Version)
+	testza.AssertFalse(t, profile.HasMod("PrivateMod"))
+	testza.AssertFalse(t, mods[2].Added)
+	testza.AssertEqual(t, "not found in the repository", mods[2].Reason)
+
+	_, _, err = ctx.Profiles.AddProfileFromSave(context.Background(), ctx, header, "FromSave")
+	testza.AssertNotNil(t, err)
+
+	vanilla, err := ReadSaveHeader(bytes.NewReader(saveHeaderFixture(13, "Vanilla", "", false)))
+	testza.AssertNoError(t, err)
+
+	_, _, err = ctx.Profiles.AddProfileFromSave(context.Background(), ctx, vanilla, "Vanilla")
+	testza.AssertNotNil(t, err)
+	testza.AssertNil(t, ctx.Profiles.GetProfile("Vanilla"))
+
+	withNull := saveHeaderFixture(13, "Null", `{"Version":1,"Mods":[null,{"Reference":"SML","Version":"3.6.0"}]}`, true)
+	header, err = ReadSaveHeader(bytes.NewReader(withNull))
+	testza.AssertNoError(t, err)
+
+	profile, mods, err = ctx.Profiles.AddProfileFromSave(context.Background(), ctx, header, "WithNull")
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, mods, 1)
+	testza.AssertTrue(t, profile.HasMod("SML"))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = ctx.Profiles.AddProfileFromSave(cancelled, ctx, header, "Cancelled")
+	testza.AssertErrorIs(t, err, context.Canceled)
+	testza.AssertNil(t, ctx.Profiles.GetProfile("Cancelled"))
+
+	testza.AssertNoError(t, installation.SetProfile(ctx, "FromSave"))
+
+	err = installation.Install(context.Background(), ctx, InstallOptions{}, installWatcher())
+	testza.AssertNoError(t, err)
+}
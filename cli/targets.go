@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mircearoata/pubgrub-go/pubgrub/semver"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+)
+
+// AllTargets lists every target a profile can require, in display order
+var AllTargets = []resolver.TargetName{
+	resolver.TargetNameWindows,
+	resolver.TargetNameWindowsServer,
+	resolver.TargetNameLinuxServer,
+}
+
+var clientTargets = map[resolver.TargetName]bool{
+	resolver.TargetNameWindows: true,
+}
+
+// ParseTargetName returns the target matching the provided name, ignoring case
+func ParseTargetName(name string) (resolver.TargetName, error) {
+	for _, target := range AllTargets {
+		if strings.EqualFold(string(target), name) {
+			return target, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown target: %s", name)
+}
+
+// TargetExclusion describes a mod version that is missing some of the required targets of a profile
+type TargetExclusion struct {
+	ModReference   string
+	Version        string
+	MissingTargets []resolver.TargetName
+
+	// Skipped is true if the version could not be picked at all,
+	// otherwise it was picked, but will not be installed on the missing targets
+	Skipped bool
+}
+
+func (e TargetExclusion) String() string {
+	missing := make([]string, len(e.MissingTargets))
+	for i, target := range e.MissingTargets {
+		missing[i] = string(target)
+	}
+
+	if e.Skipped {
+		return fmt.Sprintf("%s@%s was skipped because it is missing required targets: %s", e.ModReference, e.Version, strings.Join(missing, ", "))
+	}
+
+	return fmt.Sprintf("%s@%s will not be installed on: %s", e.ModReference, e.Version, strings.Join(missing, ", "))
+}
+
+// TargetResolveError is returned when resolving a profile failed
+// and some mod versions were skipped because of the required targets
+type TargetResolveError struct {
+	Err        error
+	Exclusions []TargetExclusion
+}
+
+func (e TargetResolveError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	b.WriteString("\n\nThe following mod versions were skipped because of the profile's required targets:")
+	for _, exclusion := range e.Exclusions {
+		b.WriteString("\n  ")
+		b.WriteString(exclusion.String())
+	}
+	return b.String()
+}
+
+func (e TargetResolveError) Unwrap() error {
+	return e.Err
+}
+
+// missingTargets returns the required targets the mod version does not provide,
+// and whether the resolver will still consider the version as usable.
+//
+// The usability rules mirror the unexported matchesTargetRequirements of ficsit-resolver (v0.0.6), which cannot be called
+// from here. They only explain resolution errors and never change what gets resolved, but must be checked again
+// whenever ficsit-resolver is updated.
+func missingTargets(modVersion resolver.ModVersion, requiredTargets []resolver.TargetName) ([]resolver.TargetName, bool) {
+	if len(requiredTargets) == 0 {
+		return nil, true
+	}
+
+	var missing []resolver.TargetName
+	hasClient, hasServer := false, false
+	missingClient, missingServer := false, false
+
+	for _, target := range requiredTargets {
+		isClient := clientTargets[target]
+		if isClient {
+			hasClient = true
+		} else {
+			hasServer = true
+		}
+
+		if slices.ContainsFunc(modVersion.Targets, func(t resolver.Target) bool {
+			return t.TargetName == target
+		}) {
+			continue
+		}
+
+		missing = append(missing, target)
+		if isClient {
+			missingClient = true
+		} else {
+			missingServer = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil, true
+	}
+
+	if modVersion.RequiredOnRemote {
+		return missing, false
+	}
+
+	// Mods not required on remote only need to support one side completely
+	return missing, (hasClient && !missingClient) || (hasServer && !missingServer)
+}
+
+// SkippedForTargets walks the enabled mods of the profile and their dependencies,
+// and returns every mod version that the resolver will not consider because of the required targets
+func (p *Profile) SkippedForTargets(ctx context.Context, provider resolver.Provider) ([]TargetExclusion, error) {
+	if len(p.RequiredTargets) == 0 {
+		return nil, nil
+	}
+
+	toCheck := make(map[string]string)
+	for modReference, mod := range p.Mods {
+		if mod.Enabled {
+			toCheck[modReference] = mod.Version
+		}
+	}
+
+	checked := make(map[string]bool)
+	exclusions := make([]TargetExclusion, 0)
+
+	for len(toCheck) > 0 {
+		next := make(map[string]string)
+
+		for modReference, condition := range toCheck {
+			if checked[modReference] {
+				continue
+			}
+			checked[modReference] = true
+
+			constraint, err := semver.NewConstraint(condition)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse constraint %s: %w", condition, err)
+			}
+
+			versions, err := p.modVersions(ctx, provider, modReference)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch mod %s: %w", modReference, err)
+			}
+
+			for _, modVersion := range versions {
+				v, err := semver.NewVersion(modVersion.Version)
+				if err != nil || !constraint.Contains(v) {
+					continue
+				}
+
+				missing, usable := missingTargets(modVersion, p.RequiredTargets)
+				if !usable {
+					exclusions = append(exclusions, TargetExclusion{
+						ModReference:   modReference,
+						Version:        modVersion.Version,
+						MissingTargets: missing,
+						Skipped:        true,
+					})
+				}
+
+				for _, dependency := range modVersion.Dependencies {
+					if dependency.Optional || checked[dependency.ModID] {
+						continue
+					}
+					if _, ok := next[dependency.ModID]; !ok {
+						next[dependency.ModID] = dependency.Condition
+					}
+				}
+			}
+		}
+
+		toCheck = next
+	}
+
+	sortExclusions(exclusions)
+
+	return exclusions, nil
+}
+
+// ExcludedFromTargets returns the mods in the lockfile that will not be installed
+// on some of the profile's required targets
+func (p *Profile) ExcludedFromTargets(lockFile *resolver.LockFile) []TargetExclusion {
+	exclusions := make([]TargetExclusion, 0)
+	if lockFile == nil || len(p.RequiredTargets) == 0 {
+		return exclusions
+	}
+
+	for modReference, mod := range lockFile.Mods {
+		var missing []resolver.TargetName
+		for _, target := range p.RequiredTargets {
+			if _, ok := mod.Targets[string(target)]; !ok {
+				missing = append(missing, target)
+			}
+		}
+
+		if len(missing) > 0 {
+			exclusions = append(exclusions, TargetExclusion{
+				ModReference:   modReference,
+				Version:        mod.Version,
+				MissingTargets: missing,
+			})
+		}
+	}
+
+	sortExclusions(exclusions)
+
+	return exclusions
+}
+
+func sortExclusions(exclusions []TargetExclusion) {
+	sort.Slice(exclusions, func(i, j int) bool {
+		if exclusions[i].ModReference != exclusions[j].ModReference {
+			return exclusions[i].ModReference < exclusions[j].ModReference
+		}
+		return exclusions[i].Version < exclusions[j].Version
+	})
+}
+
+// explainResolveError attaches the mod versions skipped because of the profile's required targets to a resolution error
+func explainResolveError(ctx context.Context, global *GlobalContext, profile *Profile, err error) error {
+	exclusions, explainErr := profile.SkippedForTargets(ctx, global.Provider)
+	if explainErr != nil {
+		slog.Warn("failed checking required targets", slog.Any("err", explainErr))
+		return err
+	}
+
+	if len(exclusions) == 0 {
+		return err
+	}
+
+	return TargetResolveError{
+		Err:        err,
+		Exclusions: exclusions,
+	}
+}
+
+func logTargetExclusions(profile *Profile, lockFile *resolver.LockFile) {
+	for _, exclusion := range profile.ExcludedFromTargets(lockFile) {
+		slog.Info(exclusion.String(), slog.String("mod_reference", exclusion.ModReference), slog.String("version", exclusion.Version))
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
@@ -238,6 +239,53 @@ func (m MockProvider) ModVersionsWithDependencies(ctx context.Context, modID str
 				RequiredOnRemote: false,
 			},
 		}, nil
+	case "OptionalIntegrationMod":
+		return []resolver.ModVersion{
+			{
+				Version: "1.0.0",
+				Dependencies: []resolver.Dependency{
+					{
+						ModID:     "SML",
+						Condition: "^3.6.0",
+						Optional:  false,
+					},
+					{
+						ModID:     "ModularUI",
+						Condition: "^2.1.11",
+						Optional:  true,
+					},
+				},
+				Targets:          commonTargets,
+				RequiredOnRemote: true,
+			},
+		}, nil
+	case "UnavailableIntegrationMod":
+		return []resolver.ModVersion{
+			{
+				Version: "1.0.0",
+				Dependencies: []resolver.Dependency{
+					{
+						ModID:     "SML",
+						Condition: "^3.6.0",
+						Optional:  false,
+					},
+					{
+						ModID:     "ModularUI",
+						Condition: "^9.0.0",
+						Optional:  true,
+					},
+					{
+						ModID:     "RemovedMod",
+						Condition: "^1.0.0",
+						Optional:  true,
+					},
+				},
+				Targets:          commonTargets,
+				RequiredOnRemote: true,
+			},
+		}, nil
+	case "RemovedMod":
+		return nil, fmt.Errorf("%w: %s", provider.ErrModNotFound, modID)
 	}
 
 	return m.MockProvider.ModVersionsWithDependencies(ctx, modID) // nolint
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"slices"
 	"sync"
 
 	"github.com/spf13/cobra"
@@ -10,40 +13,60 @@ import (
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
+func init() {
+	applyCmd.Flags().StringSlice("tag", nil, "Apply to all installations with the tag")
+	applyCmd.Flags().Bool("force", false, "Apply even if the game or server is running")
+	applyCmd.Flags().Bool("overwrite-configs", false, "Deploy the config files of the profiles even if they were edited on the installations")
+	applyCmd.Flags().Duration("wait", 0, "How long to wait for a running game or server to stop, instead of refusing to apply")
+	applyCmd.Flags().Bool("plan", false, "Apply to copies of the installations in memory, and print the changes instead of making them")
+}
+
 var applyCmd = &cobra.Command{
 	Use:   "apply [installation] ...",
-	Short: "Apply profiles to all installations",
+	Short: "Apply profiles to all installations, or those selected by name, path or tag",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		var wg sync.WaitGroup
-		errored := false
-		for _, installation := range global.Installations.Installations {
-			if len(args) > 0 {
-				found := false
-
-				for _, installPath := range args {
-					if installation.Path == installPath {
-						found = true
-						break
-					}
-				}
+		installations := global.Installations.Installations
 
-				if !found {
-					continue
-				}
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if len(args) > 0 || len(tags) > 0 {
+			installations, err = global.Installations.SelectInstallations(args, tags)
+			if err != nil {
+				return err
 			}
+		}
 
+		force, _ := cmd.Flags().GetBool("force")
+		overwriteConfigs, _ := cmd.Flags().GetBool("overwrite-configs")
+		wait, _ := cmd.Flags().GetDuration("wait")
+		options := cli.InstallOptions{
+			Force:            force,
+			OverwriteConfigs: overwriteConfigs,
+			Wait:             wait,
+		}
+
+		// Interrupting stops all installations, removing partially extracted mods
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		if plan, _ := cmd.Flags().GetBool("plan"); plan {
+			return planInstallations(ctx, global, installations, options)
+		}
+
+		var wg sync.WaitGroup
+		errored := false
+		for _, installation := range installations {
 			wg.Add(1)
 
 			go func(installation *cli.Installation) {
 				defer wg.Done()
-				if err := installation.Install(global, nil); err != nil {
+				if err := installation.Install(ctx, global, options, nil); err != nil {
 					errored = true
-					slog.Error("installation failed", slog.Any("err", err))
+					slog.Error("installation failed", slog.String("installation", installation.DisplayName()), slog.Any("err", err))
 				}
 			}(installation)
 		}
@@ -57,3 +80,45 @@ var applyCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// planInstallations prints the mods applying would add (+), update (~) and remove (-) on each installation
+func planInstallations(ctx context.Context, global *cli.GlobalContext, installations []*cli.Installation, options cli.InstallOptions) error {
+	errored := false
+	for _, installation := range installations {
+		changes, err := installation.Plan(ctx, global, options)
+		if err != nil {
+			errored = true
+			slog.Error("planning failed", slog.String("installation", installation.DisplayName()), slog.Any("err", err))
+			continue
+		}
+
+		println(installation.DisplayName() + ":")
+
+		if len(changes.Added) == 0 && len(changes.Updated) == 0 && len(changes.Removed) == 0 {
+			println("  no changes")
+			continue
+		}
+
+		printPlannedMods("+", changes.Added)
+		printPlannedMods("~", changes.Updated)
+		printPlannedMods("-", changes.Removed)
+	}
+
+	if errored {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+func printPlannedMods(prefix string, mods map[string]string) {
+	references := make([]string, 0, len(mods))
+	for reference := range mods {
+		references = append(references, reference)
+	}
+	slices.Sort(references)
+
+	for _, reference := range references {
+		println("  " + prefix + " " + reference + "@" + mods[reference])
+	}
+}
@@ -25,6 +25,6 @@ var cliCmd = &cobra.Command{
 			return err
 		}
 
-		return tea.RunTea(global)
+		return tea.RunTea(cmd.Context(), global)
 	},
 }
@@ -7,6 +7,10 @@ import (
 )
 
 func init() {
+	addCmd.Flags().String("name", "", "Name of the installation, usable instead of its path")
+	addCmd.Flags().StringSlice("tag", nil, "Tags of the installation")
+	addCmd.Flags().String("notes", "", "Notes about the installation")
+
 	Cmd.AddCommand(addCmd)
 }
 
@@ -25,11 +29,21 @@ var addCmd = &cobra.Command{
 			profile = args[1]
 		}
 
-		_, err = global.Installations.AddInstallation(global, args[0], profile)
+		installation, err := global.Installations.AddInstallation(cmd.Context(), global, args[0], profile)
 		if err != nil {
 			return err
 		}
 
+		name, _ := cmd.Flags().GetString("name")
+		if err := global.Installations.SetInstallationName(installation, name); err != nil {
+			return err
+		}
+
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		installation.AddTags(tags...)
+
+		installation.Notes, _ = cmd.Flags().GetString("notes")
+
 		return global.Save()
 	},
 }
@@ -0,0 +1,57 @@
+package installation
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	adoptCmd.Flags().String("profile", "", "Name of the profile to create")
+	_ = adoptCmd.MarkFlagRequired("profile")
+
+	Cmd.AddCommand(adoptCmd)
+}
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path>",
+	Short: "Create a profile from the mods installed manually or by other tools",
+	Long: `Create a profile from the mods installed manually or by other tools, pinned to their installed versions,
+and add the installation with that profile if it was not added yet.
+Mods of which the files match the repository are managed from then on, the others are reinstalled on the next apply.
+Mods which are not in the repository are kept, but not added to the profile.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile, _ := cmd.Flags().GetString("profile")
+
+		_, mods, err := global.Installations.Adopt(cmd.Context(), global, args[0], profile)
+		if err != nil {
+			return err
+		}
+
+		for _, mod := range mods {
+			line := mod.Reference
+			if mod.Version != "" {
+				line += "@" + mod.Version
+			}
+
+			switch {
+			case mod.Managed:
+				line += " - adopted"
+			case mod.Added:
+				line += " - added to profile, " + mod.Reason
+			default:
+				line += " - skipped, " + mod.Reason
+			}
+
+			println(line)
+		}
+
+		return global.Save()
+	},
+}
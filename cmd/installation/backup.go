@@ -0,0 +1,99 @@
+package installation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	backupCmd.Flags().Bool("list", false, "List the backups instead of creating one")
+	backupCmd.Flags().Int("keep", 0, "Delete the oldest manual backups, keeping this many")
+
+	Cmd.AddCommand(backupCmd)
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <installation>",
+	Short: "Back up the Mods directory of an installation, or list its backups",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		list, _ := cmd.Flags().GetBool("list")
+		if list {
+			backups, err := installation.Backups()
+			if err != nil {
+				return err
+			}
+
+			if len(backups) == 0 {
+				println("no backups")
+				return nil
+			}
+
+			for _, backup := range backups {
+				println(formatBackup(backup))
+			}
+
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		backup, err := installation.Backup(ctx)
+		if err != nil {
+			return err
+		}
+
+		println("created", formatBackup(backup))
+
+		keep, _ := cmd.Flags().GetInt("keep")
+		if keep <= 0 {
+			return nil
+		}
+
+		pruned, err := installation.PruneBackups(keep, false)
+		if err != nil {
+			return err
+		}
+
+		for _, backup := range pruned {
+			println("deleted", formatBackup(backup))
+		}
+
+		return nil
+	},
+}
+
+func formatBackup(backup *cli.Backup) string {
+	kind := ""
+	if backup.Automatic {
+		kind = " (automatic)"
+	}
+
+	return fmt.Sprintf(
+		"%s - %s - %s, %s of files%s",
+		backup.Name,
+		backup.Time.Local().Format(time.DateTime),
+		humanize.Bytes(uint64(backup.Size)),
+		humanize.Bytes(uint64(backup.ContentSize)),
+		kind,
+	)
+}
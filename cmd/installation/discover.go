@@ -0,0 +1,64 @@
+package installation
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	discoverCmd.Flags().Bool("add", false, "Add all discovered installations which have not been added yet")
+
+	Cmd.AddCommand(discoverCmd)
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover [profile]",
+	Short: "Find installations on this machine",
+	Long:  "Find installations of the game and dedicated server installed through Steam, SteamCMD, LinuxGSM or the Epic Games Launcher",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.SelectedProfile
+		if len(args) > 0 {
+			profile = args[0]
+		}
+
+		add, err := cmd.Flags().GetBool("add")
+		if err != nil {
+			return err
+		}
+
+		discovered := global.Installations.Discover(cmd.Context(), global)
+		if len(discovered) == 0 {
+			println("no installations found")
+			return nil
+		}
+
+		for _, install := range discovered {
+			status := ""
+			if install.Added {
+				status = " (already added)"
+			} else if add {
+				if _, err := global.Installations.AddInstallation(cmd.Context(), global, install.Path, profile); err != nil {
+					return fmt.Errorf("failed to add %s: %w", install.Path, err)
+				}
+				status = " (added)"
+			}
+
+			println(install.Path, "-", install.Platform, "-", install.Source+status)
+		}
+
+		if !add {
+			return nil
+		}
+
+		return global.Save()
+	},
+}
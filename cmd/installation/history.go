@@ -0,0 +1,65 @@
+package installation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history <installation>",
+	Short: "List the applies of an installation, which can be rolled back to",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		history, err := installation.History()
+		if err != nil {
+			return err
+		}
+
+		if len(history) == 0 {
+			println("no applies recorded")
+			return nil
+		}
+
+		for _, entry := range history {
+			source := entry.Changes.Profile
+			switch {
+			case entry.RollbackOf != 0:
+				source = fmt.Sprintf("rollback to %d", entry.RollbackOf)
+			case entry.Changes.Vanilla:
+				source = "vanilla"
+			}
+
+			println(fmt.Sprintf(
+				"%d - %s - %s - %d mods (%d added, %d updated, %d removed)",
+				entry.ID,
+				entry.Time.Local().Format(time.DateTime),
+				source,
+				len(entry.Changes.Mods),
+				len(entry.Changes.Added),
+				len(entry.Changes.Updated),
+				len(entry.Changes.Removed),
+			))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package hook
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	addCmd.Flags().String("command", "", "Shell command to run")
+	addCmd.Flags().Bool("remote", false, "Run the command on the server of the installation instead of locally")
+	addCmd.Flags().String("url", "", "URL receiving the changes as JSON in a POST request")
+	addCmd.Flags().Bool("ignore-failure", false, "Continue applying if the hook fails")
+	addCmd.MarkFlagsMutuallyExclusive("command", "url")
+	addCmd.MarkFlagsOneRequired("command", "url")
+
+	Cmd.AddCommand(addCmd)
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add <installation> <event>",
+	Short: "Add a hook to an installation",
+	Long: "Add a hook to an installation.\n\n" +
+		"Events are " + eventNames() + ".\n" +
+		"Commands receive the changes in FICSIT_* environment variables, and a non-zero exit code aborts the apply.",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		hook := cli.Hook{
+			Event: cli.HookEvent(args[1]),
+			Type:  cli.HookTypeLocal,
+		}
+
+		hook.Command, _ = cmd.Flags().GetString("command")
+		hook.URL, _ = cmd.Flags().GetString("url")
+		hook.IgnoreFailure, _ = cmd.Flags().GetBool("ignore-failure")
+
+		if remote, _ := cmd.Flags().GetBool("remote"); remote {
+			hook.Type = cli.HookTypeRemote
+		}
+
+		if hook.URL != "" {
+			hook.Type = cli.HookTypeHTTP
+		}
+
+		if err := installation.AddHook(hook); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
+
+func eventNames() string {
+	names := make([]string, len(cli.HookEvents))
+	for i, event := range cli.HookEvents {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ", ")
+}
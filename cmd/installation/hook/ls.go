@@ -0,0 +1,37 @@
+package hook
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(lsCmd)
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls <installation>",
+	Short: "List the hooks of an installation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		for i, hook := range installation.Hooks {
+			println(strconv.Itoa(i), "-", hook.String())
+		}
+
+		return nil
+	},
+}
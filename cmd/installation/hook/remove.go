@@ -0,0 +1,43 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(removeCmd)
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <installation> <index>",
+	Short: "Remove a hook from an installation, by its index in hook ls",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid hook index: %w", err)
+		}
+
+		if err := installation.RemoveHook(index); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
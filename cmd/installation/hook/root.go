@@ -0,0 +1,10 @@
+package hook
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the hooks run when applying an installation",
+}
@@ -1,6 +1,8 @@
 package installation
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
@@ -21,7 +23,20 @@ var lsCmd = &cobra.Command{
 		}
 
 		for _, install := range global.Installations.Installations {
-			println(install.Path, "-", install.Profile)
+			line := install.Path + " - " + install.Profile
+			if install.Name != "" {
+				line = install.Name + " (" + install.Path + ") - " + install.Profile
+			}
+
+			if len(install.Tags) > 0 {
+				line += " [" + strings.Join(install.Tags, ", ") + "]"
+			}
+
+			println(line)
+
+			if install.Notes != "" {
+				println("  " + strings.ReplaceAll(install.Notes, "\n", "\n  "))
+			}
 		}
 
 		return nil
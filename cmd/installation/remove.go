@@ -11,7 +11,7 @@ func init() {
 }
 
 var removeCmd = &cobra.Command{
-	Use:   "remove <path>",
+	Use:   "remove <installation>",
 	Short: "Remove an installation",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
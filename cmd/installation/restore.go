@@ -0,0 +1,62 @@
+package installation
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	restoreCmd.Flags().Bool("force", false, "Restore even if the game or server is running")
+	restoreCmd.Flags().Duration("wait", 0, "How long to wait for a running game or server to stop, instead of refusing to restore")
+
+	Cmd.AddCommand(restoreCmd)
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <installation> [backup]",
+	Short: "Replace the Mods directory of an installation with a backup, or with the latest backup if none is given",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+
+		backup, err := installation.FindBackup(name)
+		if err != nil {
+			return err
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		wait, _ := cmd.Flags().GetDuration("wait")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		if err := installation.Restore(ctx, global, backup, cli.InstallOptions{
+			Force: force,
+			Wait:  wait,
+		}); err != nil {
+			return err
+		}
+
+		println("restored", formatBackup(backup))
+
+		return nil
+	},
+}
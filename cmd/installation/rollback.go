@@ -0,0 +1,59 @@
+package installation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	rollbackCmd.Flags().Bool("force", false, "Roll back even if the game or server is running")
+	rollbackCmd.Flags().Duration("wait", 0, "How long to wait for a running game or server to stop, instead of refusing to roll back")
+
+	Cmd.AddCommand(rollbackCmd)
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <installation> [id]",
+	Short: "Reinstall the mods and configs of an earlier apply, or of the apply before the latest if no id is given",
+	Long: `Reinstall the mods and configs of an earlier apply, or of the apply before the latest if no id is given.
+The ids are listed by the history command. Mods are reinstalled from the download cache when available.
+The profile is not changed, so the next apply installs the mods and configs of the profile again.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		id := 0
+		if len(args) > 1 {
+			id, err = strconv.Atoi(args[1])
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid history id: %s", args[1])
+			}
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		wait, _ := cmd.Flags().GetDuration("wait")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return installation.Rollback(ctx, global, id, cli.InstallOptions{
+			Force: force,
+			Wait:  wait,
+		}, nil)
+	},
+}
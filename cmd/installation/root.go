@@ -2,8 +2,14 @@ package installation
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cmd/installation/hook"
 )
 
+func init() {
+	Cmd.AddCommand(hook.Cmd)
+}
+
 var Cmd = &cobra.Command{
 	Use:   "installation",
 	Short: "Manage installations",
@@ -0,0 +1,43 @@
+package installation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setAutoBackupsCmd)
+}
+
+var setAutoBackupsCmd = &cobra.Command{
+	Use:   "set-auto-backups <installation> <keep>",
+	Short: "Back up the Mods directory before applying updates or removes mods, keeping this many automatic backups",
+	Long: `Back up the Mods directory before applying updates or removes mods, keeping this many automatic backups.
+Automatic backups are restored like manual backups, and do not count towards the retention of manual backups. 0 disables them.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		keep, err := strconv.Atoi(args[1])
+		if err != nil || keep < 0 {
+			return fmt.Errorf("invalid amount of backups: %s", args[1])
+		}
+
+		installation.AutoBackups = keep
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,41 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setNameCmd)
+}
+
+var setNameCmd = &cobra.Command{
+	Use:   "set-name <installation> [name]",
+	Short: "Change the name of an installation, or remove it if no name is given",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+
+		if err := global.Installations.SetInstallationName(installation, name); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,37 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setNotesCmd)
+}
+
+var setNotesCmd = &cobra.Command{
+	Use:   "set-notes <installation> [notes]",
+	Short: "Change the notes of an installation, or remove them if no notes are given",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		installation.Notes = ""
+		if len(args) > 1 {
+			installation.Notes = args[1]
+		}
+
+		return global.Save()
+	},
+}
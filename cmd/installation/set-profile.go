@@ -9,36 +9,37 @@ import (
 )
 
 func init() {
+	setProfileCmd.Flags().StringSlice("tag", nil, "Change the profile of all installations with the tag")
+
 	Cmd.AddCommand(setProfileCmd)
 }
 
 var setProfileCmd = &cobra.Command{
-	Use:   "set-profile <path> <profile>",
-	Short: "Change the profile of an installation",
-	Args:  cobra.ExactArgs(2),
+	Use:   "set-profile [installation] ... <profile>",
+	Short: "Change the profile of installations, selected by name, path or tag",
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
-		var installation *cli.Installation
-		for _, install := range global.Installations.Installations {
-			if install.Path == args[0] {
-				installation = install
-				break
-			}
-		}
-
-		if installation == nil {
-			return errors.New("installation not found")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if len(args) < 2 && len(tags) == 0 {
+			return errors.New("no installation selected")
 		}
 
-		err = installation.SetProfile(global, args[1])
+		installations, err := global.Installations.SelectInstallations(args[:len(args)-1], tags)
 		if err != nil {
 			return err
 		}
 
+		for _, installation := range installations {
+			if err := installation.SetProfile(global, args[len(args)-1]); err != nil {
+				return err
+			}
+		}
+
 		return global.Save()
 	},
 }
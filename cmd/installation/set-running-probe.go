@@ -0,0 +1,41 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setRunningProbeCmd)
+}
+
+var setRunningProbeCmd = &cobra.Command{
+	Use:   "set-running-probe <installation> [command]",
+	Short: "Change the command which checks if the game or server is running, or remove it if no command is given",
+	Long: `Change the command which checks if the game or server is running before applying.
+The game is running if the command exits successfully, for example "pgrep -f FactoryServer" or "systemctl is-active satisfactory".
+The command runs on the server of SFTP installations, and on this machine otherwise.
+Without a command, local installations are checked for running processes, and remote installations are not checked.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		installation.RunningProbe = ""
+		if len(args) > 1 {
+			installation.RunningProbe = args[1]
+		}
+
+		return global.Save()
+	},
+}
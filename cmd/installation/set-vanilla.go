@@ -11,14 +11,14 @@ import (
 
 func init() {
 	setVanillaCmd.Flags().BoolP("off", "o", false, "Disable vanilla")
+	setVanillaCmd.Flags().StringSlice("tag", nil, "Change all installations with the tag")
 
 	Cmd.AddCommand(setVanillaCmd)
 }
 
 var setVanillaCmd = &cobra.Command{
-	Use:   "set-vanilla <path>",
-	Short: "Set the installation to vanilla mode or not",
-	Args:  cobra.ExactArgs(1),
+	Use:   "set-vanilla [installation] ...",
+	Short: "Set installations, selected by name, path or tag, to vanilla mode or not",
 	PreRun: func(cmd *cobra.Command, args []string) {
 		_ = viper.BindPFlag("off", cmd.Flags().Lookup("off"))
 	},
@@ -28,19 +28,19 @@ var setVanillaCmd = &cobra.Command{
 			return err
 		}
 
-		var installation *cli.Installation
-		for _, install := range global.Installations.Installations {
-			if install.Path == args[0] {
-				installation = install
-				break
-			}
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		if len(args) == 0 && len(tags) == 0 {
+			return errors.New("no installation selected")
 		}
 
-		if installation == nil {
-			return errors.New("installation not found")
+		installations, err := global.Installations.SelectInstallations(args, tags)
+		if err != nil {
+			return err
 		}
 
-		installation.Vanilla = !viper.GetBool("off")
+		for _, installation := range installations {
+			installation.Vanilla = !viper.GetBool("off")
+		}
 
 		return global.Save()
 	},
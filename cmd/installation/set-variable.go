@@ -0,0 +1,39 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(setVariableCmd)
+}
+
+var setVariableCmd = &cobra.Command{
+	Use:   "set-variable <installation> <name> [value]",
+	Short: "Set a variable used by the config templates of the profile, or remove it if no value is given",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		value := ""
+		if len(args) > 2 {
+			value = args[2]
+		}
+
+		installation.SetVariable(args[1], value)
+
+		return global.Save()
+	},
+}
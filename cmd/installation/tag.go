@@ -0,0 +1,41 @@
+package installation
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	tagCmd.Flags().Bool("remove", false, "Remove the tags instead")
+
+	Cmd.AddCommand(tagCmd)
+}
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <installation> <tag> ...",
+	Short: "Tag an installation",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		remove, _ := cmd.Flags().GetBool("remove")
+		if remove {
+			installation.RemoveTags(args[1:]...)
+		} else {
+			installation.AddTags(args[1:]...)
+		}
+
+		return global.Save()
+	},
+}
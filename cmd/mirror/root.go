@@ -0,0 +1,10 @@
+package mirror
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Mirror SMR for other ficsit instances",
+}
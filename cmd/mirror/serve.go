@@ -0,0 +1,47 @@
+package mirror
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/mirror"
+)
+
+func init() {
+	serveCmd.Flags().String("listen", ":8088", "Address to listen on")
+	serveCmd.Flags().Duration("refresh-interval", 5*time.Minute, "How long mod versions are served from the local registry before being fetched from upstream again")
+
+	Cmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the local registry and download cache as an SMR-compatible API",
+	Long:  "Serve the local registry and download cache as an SMR-compatible API.\n\nOther ficsit instances can use the mirror by setting --api-base to its address.\nAnything missing locally is fetched from the upstream --api-base of this instance.",
+	Args:  cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("listen", cmd.Flags().Lookup("listen"))
+		_ = viper.BindPFlag("refresh-interval", cmd.Flags().Lookup("refresh-interval"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := cli.InitCLI(false); err != nil {
+			return err
+		}
+
+		server := mirror.NewServer(viper.GetDuration("refresh-interval"), viper.GetBool("offline"))
+
+		slog.Info("serving mirror", slog.String("listen", viper.GetString("listen")), slog.String("upstream", viper.GetString("api-base")))
+
+		if err := http.ListenAndServe(viper.GetString("listen"), server); err != nil { //nolint:gosec
+			return fmt.Errorf("failed to serve mirror: %w", err)
+		}
+
+		return nil
+	},
+}
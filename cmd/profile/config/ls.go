@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(lsCmd)
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls <profile>",
+	Short: "List the config files of a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		paths := make([]string, 0, len(profile.Configs))
+		for configPath := range profile.Configs {
+			paths = append(paths, configPath)
+		}
+		slices.Sort(paths)
+
+		for _, configPath := range paths {
+			if profile.Configs[configPath].Template {
+				println(configPath, "(template)")
+			} else {
+				println(configPath)
+			}
+		}
+
+		return nil
+	},
+}
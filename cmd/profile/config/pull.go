@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(pullCmd)
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <installation> [path]...",
+	Short: "Copy config files from an installation into its profile",
+	Long: `Copy config files from an installation into its profile.
+Paths are relative to the installation, like FactoryGame/Saved/Config/WindowsServer/Game.ini.
+Without paths, all files in FactoryGame/Configs and the configs already in the profile are pulled,
+except templates, which are only replaced when their path is given.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		pulled, err := installation.PullConfigs(cmd.Context(), global, args[1:])
+		if err != nil {
+			return err
+		}
+
+		if len(pulled) == 0 {
+			println("no config files found")
+			return nil
+		}
+
+		for _, configPath := range pulled {
+			println("pulled", configPath, "into", installation.Profile)
+		}
+
+		return global.Save()
+	},
+}
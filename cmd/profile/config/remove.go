@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(removeCmd)
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <profile> <path>",
+	Short: "Remove a config file from a profile, keeping it on the installations",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		if err := profile.RemoveConfig(args[1]); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,10 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage config files deployed with a profile",
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	setCmd.Flags().Bool("template", false, "Render the file as a Go template with the variables of each installation")
+
+	Cmd.AddCommand(setCmd)
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set <profile> <path> <file>",
+	Short: "Add or replace a config file of a profile with the contents of a local file",
+	Long: `Add or replace a config file of a profile with the contents of a local file.
+The path is relative to the installation, like FactoryGame/Configs/AreaActions.cfg.
+Templates can use the variables of the installation, set with "installation set-variable", like {{ .port }}.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		content, err := os.ReadFile(args[2])
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		template, _ := cmd.Flags().GetBool("template")
+
+		if err := profile.SetConfig(args[1], string(content), template); err != nil {
+			return err
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(excludeCmd)
+}
+
+var excludeCmd = &cobra.Command{
+	Use:       "exclude <profile> [add|remove] [mod-references...]",
+	Short:     "List or change the mods that must never be installed with a profile",
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: []string{"add", "remove"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		if len(args) == 1 {
+			for _, reference := range profile.ExcludedMods {
+				println(reference)
+			}
+			return nil
+		}
+
+		if len(args) == 2 {
+			return errors.New("no mods provided")
+		}
+
+		switch args[1] {
+		case "add":
+			if err := profile.ExcludeMods(args[2:]...); err != nil {
+				return err
+			}
+		case "remove":
+			profile.IncludeMods(args[2:]...)
+		default:
+			return fmt.Errorf("unknown action: %s", args[1])
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,25 @@
+package profile
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <name> <file>",
+	Short: "Export a profile to a file which can be imported by other users",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		return global.Profiles.ExportProfile(args[0], args[1])
+	},
+}
@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	fromInstallationCmd.Flags().String("target", "Windows", "Target of the players")
+	fromInstallationCmd.Flags().String("export", "", "Also export the profile to this file, for players to import")
+
+	Cmd.AddCommand(fromInstallationCmd)
+}
+
+var fromInstallationCmd = &cobra.Command{
+	Use:   "from-installation <server-installation> <name>",
+	Short: "Create a profile for the players of a server installation",
+	Long: `Create a profile for the players of a server installation, with the mods they need to join it,
+pinned to the versions installed on the server.
+Mods not required on remote and mods not available for the target of the players are left out,
+unless a mod the players need depends on them.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		installation := global.Installations.FindInstallation(args[0])
+		if installation == nil {
+			return errors.New("installation not found")
+		}
+
+		targetName, _ := cmd.Flags().GetString("target")
+		target, err := cli.ParseTargetName(targetName)
+		if err != nil {
+			return err
+		}
+
+		_, mods, err := global.Profiles.AddClientProfile(cmd.Context(), global, installation, args[1], target)
+		if err != nil {
+			return err
+		}
+
+		for _, mod := range mods {
+			line := mod.Reference + "@" + mod.Version
+			if mod.Added {
+				line += " - added"
+			} else {
+				line += " - skipped, " + mod.Reason
+			}
+
+			println(line)
+		}
+
+		if exportPath, _ := cmd.Flags().GetString("export"); exportPath != "" {
+			if err := global.Profiles.ExportProfile(args[1], exportPath); err != nil {
+				return err
+			}
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(fromSaveCmd)
+}
+
+var fromSaveCmd = &cobra.Command{
+	Use:   "from-save <file.sav> <name>",
+	Short: "Create a profile from the mods a save file was made with",
+	Long: `Create a profile from the mod list SML writes into the header of save files.
+Mods are added with a caret constraint on their saved version, so compatible updates are allowed.
+Mods which are not in the repository are reported, but not added to the profile.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		header, err := cli.ReadSaveHeaderFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		_, mods, err := global.Profiles.AddProfileFromSave(cmd.Context(), global, header, args[1])
+		if err != nil {
+			return err
+		}
+
+		for _, mod := range mods {
+			line := mod.Reference + "@" + mod.Version
+			if mod.Added {
+				line += " - added"
+			} else {
+				line += " - skipped, " + mod.Reason
+			}
+
+			println(line)
+		}
+
+		return global.Save()
+	},
+}
@@ -0,0 +1,36 @@
+package profile
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	importCmd.Flags().String("name", "", "Name of the imported profile, instead of its exported name")
+
+	Cmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a profile exported to a file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+
+		profile, err := global.Profiles.ImportProfile(args[0], name)
+		if err != nil {
+			return err
+		}
+
+		println("imported profile " + profile.Name)
+
+		return global.Save()
+	},
+}
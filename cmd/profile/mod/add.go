@@ -1,41 +1,122 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
 	"github.com/spf13/cobra"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/cli/cache"
 )
 
 func init() {
+	addCmd.Flags().String("file", "", "Path to a local .smod or .zip archive of the mod")
+	addCmd.Flags().String("dir", "", "Path to a local build directory of the mod")
+	addCmd.Flags().StringSlice("target", nil, "Targets a local build without per-target directories was made for")
+	addCmd.MarkFlagsMutuallyExclusive("file", "dir")
+
 	Cmd.AddCommand(addCmd)
 }
 
 var addCmd = &cobra.Command{
-	Use:   "add <profile> <mod-reference> [version]",
+	Use:   "add <profile> [mod-reference] [version]",
 	Short: "Add mod to a profile",
-	Args:  cobra.MinimumNArgs(2),
+	Long:  "Add mod to a profile.\n\nWith --file or --dir the mod is installed from the local archive or directory instead of the repository.\nThe mod reference, version and dependencies are read from the .uplugin file of the source.\nSources with per-target directories provide the targets of their directories, other sources need --target.",
+	Args:  cobra.RangeArgs(1, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, err := cli.InitCLI(false)
 		if err != nil {
 			return err
 		}
 
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		source, err := localSourceFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if source == "" {
+			if len(args) < 2 {
+				return errors.New("mod reference is required without --file or --dir")
+			}
+
+			version := ">=0.0.0"
+			if len(args) > 2 {
+				version = args[2]
+			}
+
+			if err := profile.AddMod(args[1], version); err != nil {
+				return err
+			}
+
+			return global.Save()
+		}
+
 		version := ">=0.0.0"
 		if len(args) > 2 {
 			version = args[2]
 		}
 
-		profile := global.Profiles.GetProfile(args[0])
-		if profile == nil {
-			return fmt.Errorf("profile with name %s does not exist", args[0])
+		if len(args) > 1 {
+			localSource, err := cache.ReadLocalSource(source)
+			if err != nil {
+				return fmt.Errorf("failed to read local source: %w", err)
+			}
+
+			if localSource.ModReference != args[1] {
+				return fmt.Errorf("local source contains mod %s, not %s", localSource.ModReference, args[1])
+			}
+		}
+
+		targetNames, _ := cmd.Flags().GetStringSlice("target")
+		targets := make([]resolver.TargetName, len(targetNames))
+		for i, name := range targetNames {
+			targets[i], err = cli.ParseTargetName(name)
+			if err != nil {
+				return err
+			}
 		}
 
-		if err := profile.AddMod(args[1], version); err != nil {
+		reference, err := profile.AddLocalMod(source, version, targets...)
+		if err != nil {
 			return err
 		}
 
+		println("added", reference, "from", profile.Mods[reference].Source)
+
 		return global.Save()
 	},
 }
+
+func localSourceFromFlags(cmd *cobra.Command) (string, error) {
+	if file, _ := cmd.Flags().GetString("file"); file != "" {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat file: %w", err)
+		}
+		if stat.IsDir() {
+			return "", fmt.Errorf("%s is a directory, use --dir instead", file)
+		}
+		return file, nil
+	}
+
+	if dir, _ := cmd.Flags().GetString("dir"); dir != "" {
+		stat, err := os.Stat(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat directory: %w", err)
+		}
+		if !stat.IsDir() {
+			return "", fmt.Errorf("%s is not a directory, use --file instead", dir)
+		}
+		return dir, nil
+	}
+
+	return "", nil
+}
@@ -28,6 +28,10 @@ var modsCmd = &cobra.Command{
 		}
 
 		for reference, mod := range profile.Mods {
+			if mod.Source != "" {
+				println(reference, mod.Version, "(local: "+mod.Source+")")
+				continue
+			}
 			println(reference, mod.Version)
 		}
 
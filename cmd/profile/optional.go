@@ -0,0 +1,46 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(optionalCmd)
+}
+
+var optionalCmd = &cobra.Command{
+	Use:   "optional <profile> <install|skip|default> [mod-reference]",
+	Short: "Set whether optional dependencies are installed",
+	Long:  "Set whether optional dependencies are installed for the whole profile, or override it for a single mod.\n\nUse \"default\" to remove the override of a mod.",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		policy, err := cli.ParseOptionalDependencyPolicy(args[1])
+		if err != nil {
+			return err
+		}
+
+		if len(args) > 2 {
+			if err := profile.SetModOptionalDependencies(args[2], policy); err != nil {
+				return err
+			}
+		} else {
+			profile.SetOptionalDependencies(policy)
+		}
+
+		return global.Save()
+	},
+}
@@ -2,8 +2,16 @@ package profile
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cmd/profile/config"
+	"github.com/satisfactorymodding/ficsit-cli/cmd/profile/mod"
 )
 
+func init() {
+	Cmd.AddCommand(config.Cmd)
+	Cmd.AddCommand(mod.Cmd)
+}
+
 var Cmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Manage profiles",
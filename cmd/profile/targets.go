@@ -0,0 +1,73 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/cobra"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	Cmd.AddCommand(targetsCmd)
+}
+
+var targetsCmd = &cobra.Command{
+	Use:       "targets <profile> [add|remove|set] [targets...]",
+	Short:     "List or change the targets all mods in a profile must support",
+	Long:      "List or change the targets all mods in a profile must support.\n\nValid targets: Windows, WindowsServer, LinuxServer",
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: []string{"add", "remove", "set"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		if len(args) == 1 {
+			for _, target := range profile.RequiredTargets {
+				println(target)
+			}
+			return nil
+		}
+
+		targets := make([]resolver.TargetName, 0, len(args)-2)
+		for _, name := range args[2:] {
+			target, err := cli.ParseTargetName(name)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target)
+		}
+
+		switch args[1] {
+		case "add":
+			if len(targets) == 0 {
+				return errors.New("no targets provided")
+			}
+			if err := profile.AddRequiredTargets(targets...); err != nil {
+				return err
+			}
+		case "remove":
+			if len(targets) == 0 {
+				return errors.New("no targets provided")
+			}
+			profile.RemoveRequiredTargets(targets...)
+		case "set":
+			if err := profile.SetRequiredTargets(targets...); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown action: %s", args[1])
+		}
+
+		return global.Save()
+	},
+}
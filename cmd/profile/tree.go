@@ -0,0 +1,84 @@
+package profile
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+)
+
+func init() {
+	treeCmd.Flags().Int("game-version", 0, "Game version (CL) to resolve for. Ignores game version requirements if not set")
+
+	Cmd.AddCommand(treeCmd)
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree <profile>",
+	Short: "Resolve a profile and print its dependency tree",
+	Args:  cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("game-version", cmd.Flags().Lookup("game-version"))
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, err := cli.InitCLI(false)
+		if err != nil {
+			return err
+		}
+
+		profile := global.Profiles.GetProfile(args[0])
+		if profile == nil {
+			return fmt.Errorf("profile with name %s does not exist", args[0])
+		}
+
+		gameVersion := viper.GetInt("game-version")
+		if gameVersion == 0 {
+			gameVersion = math.MaxInt
+		}
+
+		lockFile, err := profile.Resolve(global.Provider, nil, gameVersion)
+		if err != nil {
+			return err
+		}
+
+		tree, err := profile.DependencyTree(cmd.Context(), global.Provider, lockFile, gameVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range tree {
+			printDependencyTree(node, 0)
+		}
+
+		return nil
+	},
+}
+
+func printDependencyTree(node *cli.DependencyTreeNode, depth int) {
+	line := strings.Repeat("  ", depth)
+
+	if node.Optional {
+		line += "[optional] "
+	}
+
+	line += node.ModReference
+	if node.Version != "" {
+		line += "@" + node.Version
+	}
+
+	line += " (" + node.Condition + ")"
+
+	if node.Reason != "" {
+		line += " - " + string(node.Status) + ": " + node.Reason
+	}
+
+	println(line)
+
+	for _, dependency := range node.Dependencies {
+		printDependencyTree(dependency, depth+1)
+	}
+}
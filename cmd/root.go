@@ -15,6 +15,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/satisfactorymodding/ficsit-cli/cmd/installation"
+	"github.com/satisfactorymodding/ficsit-cli/cmd/mirror"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/mod"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/profile"
 	"github.com/satisfactorymodding/ficsit-cli/cmd/smr"
@@ -123,6 +124,7 @@ func init() {
 	RootCmd.AddCommand(installation.Cmd)
 	RootCmd.AddCommand(mod.Cmd)
 	RootCmd.AddCommand(smr.Cmd)
+	RootCmd.AddCommand(mirror.Cmd)
 
 	var baseLocalDir string
 
@@ -160,8 +162,14 @@ func init() {
 	RootCmd.PersistentFlags().String("graphql-api", "/v2/query", "Path for GraphQL API")
 	RootCmd.PersistentFlags().String("api-key", "", "API key to use when sending requests")
 
+	RootCmd.PersistentFlags().StringSlice("repositories", []string{}, "Static mod repositories (directories or HTTP base URLs) in priority order, use \"smr\" to position SMR")
+
 	RootCmd.PersistentFlags().Bool("offline", false, "Whether to only use local data")
 	RootCmd.PersistentFlags().Int("concurrent-downloads", 5, "Maximum number of concurrent downloads")
+	RootCmd.PersistentFlags().Bool("remote-extract", false, "Upload mod archives to SFTP installations as a whole and extract them on the server with unzip, when SSH exec is available")
+	RootCmd.PersistentFlags().Int("ftp-connections", 5, "Maximum number of concurrent connections to each FTP installation")
+	RootCmd.PersistentFlags().Duration("connect-timeout", 10*time.Second, "Timeout for connecting to servers and remote installations")
+	RootCmd.PersistentFlags().Duration("read-timeout", 30*time.Second, "Timeout for a response or download to make progress")
 
 	_ = viper.BindPFlag("log", RootCmd.PersistentFlags().Lookup("log"))
 	_ = viper.BindPFlag("log-file", RootCmd.PersistentFlags().Lookup("log-file"))
@@ -179,6 +187,12 @@ func init() {
 	_ = viper.BindPFlag("graphql-api", RootCmd.PersistentFlags().Lookup("graphql-api"))
 	_ = viper.BindPFlag("api-key", RootCmd.PersistentFlags().Lookup("api-key"))
 
+	_ = viper.BindPFlag("repositories", RootCmd.PersistentFlags().Lookup("repositories"))
+
 	_ = viper.BindPFlag("offline", RootCmd.PersistentFlags().Lookup("offline"))
 	_ = viper.BindPFlag("concurrent-downloads", RootCmd.PersistentFlags().Lookup("concurrent-downloads"))
+	_ = viper.BindPFlag("remote-extract", RootCmd.PersistentFlags().Lookup("remote-extract"))
+	_ = viper.BindPFlag("ftp-connections", RootCmd.PersistentFlags().Lookup("ftp-connections"))
+	_ = viper.BindPFlag("connect-timeout", RootCmd.PersistentFlags().Lookup("connect-timeout"))
+	_ = viper.BindPFlag("read-timeout", RootCmd.PersistentFlags().Lookup("read-timeout"))
 }
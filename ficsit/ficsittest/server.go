@@ -0,0 +1,402 @@
+// Package ficsittest provides a fake of the SMR API for tests,
+// serving the REST and GraphQL endpoints used by the ficsit package.
+package ficsittest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+// Server is a fake SMR API serving the mods added to it.
+// Point the api-base setting at its URL to use it.
+type Server struct {
+	*httptest.Server
+
+	mods      map[string]Mod
+	versions  map[string][]ficsit.ModVersion
+	downloads map[string][]byte
+	mu        sync.RWMutex
+}
+
+// Mod is a mod served by the fake API
+type Mod struct {
+	ID          string
+	Reference   string
+	Name        string
+	Description string
+	Versions    []Version
+}
+
+// Version is a version of a mod served by the fake API.
+// An archive containing the uplugin file of the mod is generated for each target.
+type Version struct {
+	Version          string
+	GameVersion      string
+	Dependencies     []ficsit.Dependency
+	Targets          []string
+	RequiredOnRemote bool
+}
+
+type graphqlRequest struct {
+	Variables     json.RawMessage `json:"variables"`
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// archiveTime is used for every file of the generated archives, so that their hashes are stable
+var archiveTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NewServer starts a fake SMR API without any mods
+func NewServer() *Server {
+	s := &Server{
+		mods:      make(map[string]Mod),
+		versions:  make(map[string][]ficsit.ModVersion),
+		downloads: make(map[string][]byte),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/mod/", s.handleVersions)
+	mux.HandleFunc("/v1/version/", s.handleDownload)
+	mux.HandleFunc("/v2/query", s.handleGraphQL)
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// AddMod adds a mod to the API, replacing any mod with the same reference
+func (s *Server) AddMod(mod Mod) {
+	if mod.ID == "" {
+		mod.ID = mod.Reference
+	}
+
+	if mod.Name == "" {
+		mod.Name = mod.Reference
+	}
+
+	versions := make([]ficsit.ModVersion, len(mod.Versions))
+	downloads := make(map[string][]byte)
+
+	for i, version := range mod.Versions {
+		versionID := mod.Reference + "-" + version.Version
+
+		targets := make([]ficsit.Target, len(version.Targets))
+		for j, target := range version.Targets {
+			archive := modArchive(mod, version, target)
+			hash := sha256.Sum256(archive)
+			link := fmt.Sprintf("/v1/version/%s/%s/download", versionID, target)
+
+			downloads[link] = archive
+			targets[j] = ficsit.Target{
+				VersionID:  versionID,
+				TargetName: target,
+				Link:       link,
+				Hash:       hex.EncodeToString(hash[:]),
+				Size:       int64(len(archive)),
+			}
+		}
+
+		versions[i] = ficsit.ModVersion{
+			ID:               versionID,
+			Version:          version.Version,
+			GameVersion:      version.GameVersion,
+			Dependencies:     version.Dependencies,
+			Targets:          targets,
+			RequiredOnRemote: version.RequiredOnRemote,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.versions[mod.Reference]; ok {
+		for _, version := range previous {
+			for _, target := range version.Targets {
+				delete(s.downloads, target.Link)
+			}
+		}
+	}
+
+	s.mods[mod.Reference] = mod
+	s.versions[mod.Reference] = versions
+	for link, archive := range downloads {
+		s.downloads[link] = archive
+	}
+}
+
+// Archive returns the archive served for the target of a mod version
+func (s *Server) Archive(modReference string, version string, target string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	archive, ok := s.downloads[fmt.Sprintf("/v1/version/%s-%s/%s/download", modReference, version, target)]
+	return archive, ok
+}
+
+// modArchive builds the archive of a mod version for a target,
+// containing its uplugin file and a file naming the target it was built for
+func modArchive(mod Mod, version Version, target string) []byte {
+	plugins := make([]map[string]any, len(version.Dependencies))
+	for i, dependency := range version.Dependencies {
+		plugins[i] = map[string]any{
+			"Name":       dependency.ModID,
+			"SemVersion": dependency.Condition,
+			"Enabled":    true,
+			"bOptional":  dependency.Optional,
+		}
+	}
+
+	uplugin, err := json.MarshalIndent(map[string]any{
+		"SemVersion":   version.Version,
+		"FriendlyName": mod.Name,
+		"Description":  mod.Description,
+		"GameVersion":  version.GameVersion,
+		"Plugins":      plugins,
+	}, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{name: mod.Reference + ".uplugin", data: uplugin},
+		{name: "Binaries/" + target + "/" + mod.Reference + ".txt", data: []byte(mod.Reference + " " + version.Version + " " + target)},
+	}
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+
+	for _, file := range files {
+		w, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: archiveTime,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		if _, err := w.Write(file.data); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
+
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	modReference, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/mod/"), "/versions/all")
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	versions, ok := s.versions[modReference]
+	s.mu.RUnlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, ficsit.AllVersionsResponse{
+			Error: &ficsit.Error{
+				Message: "mod not found",
+				Code:    404,
+			},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ficsit.AllVersionsResponse{
+		Data:    versions,
+		Success: true,
+	})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	archive, ok := s.downloads[r.URL.Path]
+	s.mu.RUnlock()
+
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeContent(w, r, "", archiveTime, bytes.NewReader(archive))
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var request graphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeGraphQLError(w, fmt.Sprintf("failed to parse request: %s", err))
+		return
+	}
+
+	var variables struct {
+		Filter  ficsit.ModFilter `json:"filter"`
+		ModID   string           `json:"modId"`
+		Version string           `json:"version"`
+	}
+	if len(request.Variables) > 0 {
+		if err := json.Unmarshal(request.Variables, &variables); err != nil {
+			writeGraphQLError(w, fmt.Sprintf("failed to parse variables: %s", err))
+			return
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var data any
+	switch request.OperationName {
+	case "Mods":
+		data = ficsit.ModsResponse{Mods: s.listMods(variables.Filter)}
+	case "GetMod":
+		mod, ok := s.findMod(variables.ModID)
+		if !ok {
+			data = map[string]any{"mod": nil}
+			break
+		}
+
+		data = ficsit.GetModResponse{Mod: ficsit.GetModMod{
+			Id:               mod.ID,
+			Mod_reference:    mod.Reference,
+			Name:             mod.Name,
+			Full_description: mod.Description,
+			Authors:          []ficsit.GetModModAuthorsUserMod{},
+		}}
+	case "GetModName":
+		mod, ok := s.findMod(variables.ModID)
+		if !ok {
+			data = map[string]any{"mod": nil}
+			break
+		}
+
+		data = ficsit.GetModNameResponse{Mod: ficsit.GetModNameMod{
+			Id:            mod.ID,
+			Mod_reference: mod.Reference,
+			Name:          mod.Name,
+		}}
+	case "Version":
+		mod, ok := s.findMod(variables.ModID)
+		if !ok {
+			data = map[string]any{"mod": nil}
+			break
+		}
+
+		response := ficsit.VersionResponse{Mod: ficsit.VersionMod{Id: mod.ID}}
+		for _, version := range s.versions[mod.Reference] {
+			if version.Version == variables.Version && len(version.Targets) > 0 {
+				response.Mod.Version = ficsit.VersionModVersion{
+					Id:      version.ID,
+					Version: version.Version,
+					Link:    version.Targets[0].Link,
+					Hash:    version.Targets[0].Hash,
+				}
+			}
+		}
+
+		data = response
+	default:
+		writeGraphQLError(w, fmt.Sprintf("operation %q is not supported by the fake API", request.OperationName))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// findMod finds a mod by its ID or reference. Must be called with the lock held.
+func (s *Server) findMod(modIDOrReference string) (Mod, bool) {
+	if mod, ok := s.mods[modIDOrReference]; ok {
+		return mod, true
+	}
+
+	for _, mod := range s.mods {
+		if mod.ID == modIDOrReference {
+			return mod, true
+		}
+	}
+
+	return Mod{}, false
+}
+
+// listMods returns the mods matching the filter ordered by name. Must be called with the lock held.
+// Unlike the real API, all mods are returned if the filter has no limit.
+func (s *Server) listMods(filter ficsit.ModFilter) ficsit.ModsModsGetMods {
+	var matching []Mod
+	for _, mod := range s.mods {
+		if filter.Search != "" &&
+			!strings.Contains(strings.ToLower(mod.Name), strings.ToLower(filter.Search)) &&
+			!strings.Contains(strings.ToLower(mod.Reference), strings.ToLower(filter.Search)) {
+			continue
+		}
+
+		if len(filter.Ids) > 0 && !slices.Contains(filter.Ids, mod.ID) {
+			continue
+		}
+
+		if len(filter.References) > 0 && !slices.Contains(filter.References, mod.Reference) {
+			continue
+		}
+
+		matching = append(matching, mod)
+	}
+
+	slices.SortFunc(matching, func(a, b Mod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	result := ficsit.ModsModsGetMods{
+		Count: len(matching),
+		Mods:  []ficsit.ModsModsGetModsModsMod{},
+	}
+
+	page := matching[min(filter.Offset, len(matching)):]
+	if filter.Limit > 0 {
+		page = page[:min(filter.Limit, len(page))]
+	}
+
+	for _, mod := range page {
+		result.Mods = append(result.Mods, ficsit.ModsModsGetModsModsMod{
+			Id:            mod.ID,
+			Name:          mod.Name,
+			Mod_reference: mod.Reference,
+		})
+	}
+
+	return result
+}
+
+func writeGraphQLError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"errors": []graphqlError{{Message: message}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
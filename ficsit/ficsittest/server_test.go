@@ -0,0 +1,104 @@
+package ficsittest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/cfg"
+	"github.com/satisfactorymodding/ficsit-cli/ficsit"
+)
+
+func init() {
+	cfg.SetDefaults()
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	server := NewServer()
+	defer server.Close()
+
+	viper.Set("api-base", server.URL)
+	defer viper.Set("api-base", "https://api.ficsit.dev")
+
+	server.AddMod(Mod{
+		ID:        "smlID",
+		Reference: "SML",
+		Name:      "Satisfactory Mod Loader",
+		Versions: []Version{
+			{Version: "3.6.0", GameVersion: ">=264901", Targets: []string{"Windows", "LinuxServer"}},
+		},
+	})
+	server.AddMod(Mod{
+		Reference: "AreaActions",
+		Name:      "Area Actions",
+		Versions: []Version{
+			{
+				Version:      "1.0.0",
+				Dependencies: []ficsit.Dependency{{ModID: "SML", Condition: "^3.6.0"}},
+				Targets:      []string{"Windows"},
+			},
+		},
+	})
+
+	client := ficsit.InitAPI()
+
+	mods, err := ficsit.Mods(ctx, client, ficsit.ModFilter{Limit: 1})
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 2, mods.Mods.Count)
+	testza.AssertLen(t, mods.Mods.Mods, 1)
+	testza.AssertEqual(t, "AreaActions", mods.Mods.Mods[0].Mod_reference)
+
+	mods, err = ficsit.Mods(ctx, client, ficsit.ModFilter{Search: "loader"})
+	testza.AssertNoError(t, err)
+	testza.AssertLen(t, mods.Mods.Mods, 1)
+	testza.AssertEqual(t, "SML", mods.Mods.Mods[0].Mod_reference)
+
+	name, err := ficsit.GetModName(ctx, client, "smlID")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "SML", name.Mod.Mod_reference)
+
+	version, err := ficsit.Version(ctx, client, "SML", "3.6.0")
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, "3.6.0", version.Mod.Version.Version)
+
+	_, err = ficsit.CreateVersion(ctx, client, "SML")
+	testza.AssertNotNil(t, err)
+
+	versions, err := ficsit.GetAllModVersions(ctx, "AreaActions")
+	testza.AssertNoError(t, err)
+	testza.AssertTrue(t, versions.Success)
+	testza.AssertLen(t, versions.Data, 1)
+	testza.AssertEqual(t, "SML", versions.Data[0].Dependencies[0].ModID)
+
+	versions, err = ficsit.GetAllModVersions(ctx, "Missing")
+	testza.AssertNoError(t, err)
+	testza.AssertNotNil(t, versions.Error)
+
+	// Downloads match the hash and size of their target
+	versions, err = ficsit.GetAllModVersions(ctx, "SML")
+	testza.AssertNoError(t, err)
+	target := versions.Data[0].Targets[1]
+
+	res, err := http.Get(server.URL + target.Link)
+	testza.AssertNoError(t, err)
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	testza.AssertNoError(t, err)
+
+	hash := sha256.Sum256(data)
+	testza.AssertEqual(t, target.Hash, hex.EncodeToString(hash[:]))
+	testza.AssertEqual(t, target.Size, int64(len(data)))
+
+	archive, ok := server.Archive("SML", "3.6.0", "LinuxServer")
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, archive, data)
+}
@@ -1,18 +1,26 @@
 package ficsit
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
 
 const allVersionEndpoint = `/v1/mod/%s/versions/all`
 
-func GetAllModVersions(modID string) (*AllVersionsResponse, error) {
-	response, err := http.DefaultClient.Get(viper.GetString("api-base") + fmt.Sprintf(allVersionEndpoint, modID))
+func GetAllModVersions(ctx context.Context, modID string) (*AllVersionsResponse, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, viper.GetString("api-base")+fmt.Sprintf(allVersionEndpoint, modID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	response, err := utils.HTTPClient().Do(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed fetching all versions: %w", err)
 	}
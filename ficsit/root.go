@@ -6,6 +6,8 @@ import (
 
 	"github.com/Khan/genqlient/graphql"
 	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/ficsit-cli/utils"
 )
 
 type AuthedTransport struct {
@@ -29,7 +31,7 @@ func (t *AuthedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 func InitAPI() graphql.Client {
 	httpClient := http.Client{
 		Transport: &AuthedTransport{
-			Wrapped: http.DefaultTransport,
+			Wrapped: utils.HTTPTransport(),
 		},
 	}
 
@@ -29,8 +29,10 @@ require (
 	github.com/satisfactorymodding/ficsit-resolver v0.0.6
 	github.com/spf13/cobra v1.8.0
 	github.com/spf13/viper v1.18.1
+	github.com/studio-b12/gowebdav v0.9.0
 	goftp.io/server/v2 v2.0.1
 	golang.org/x/crypto v0.21.0
+	golang.org/x/net v0.22.0
 	golang.org/x/sync v0.6.0
 	modernc.org/sqlite v1.32.0
 )
@@ -100,7 +102,6 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/exp v0.0.0-20231206192017-f3f8817b8deb // indirect
 	golang.org/x/mod v0.16.0 // indirect
-	golang.org/x/net v0.22.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
 	golang.org/x/term v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
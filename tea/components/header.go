@@ -32,7 +32,7 @@ func (h headerComponent) Update(_ tea.Msg) (tea.Model, tea.Cmd) {
 func (h headerComponent) View() string {
 	out := h.labelStyle.Render("Installation: ")
 	if h.root.GetCurrentInstallation() != nil {
-		out += h.root.GetCurrentInstallation().Path
+		out += h.root.GetCurrentInstallation().DisplayName()
 	} else {
 		out += "None"
 	}
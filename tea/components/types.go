@@ -1,9 +1,10 @@
 package components
 
 import (
+	"context"
+
 	"github.com/Khan/genqlient/graphql"
 	tea "github.com/charmbracelet/bubbletea"
-	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
@@ -12,6 +13,9 @@ import (
 type RootModel interface {
 	GetGlobal() *cli.GlobalContext
 
+	// GetContext returns the context of the interactive CLI, cancelled when it exits
+	GetContext() context.Context
+
 	GetCurrentProfile() *cli.Profile
 	SetCurrentProfile(profile *cli.Profile) error
 
@@ -20,7 +24,6 @@ type RootModel interface {
 
 	GetAPIClient() graphql.Client
 	GetProvider() provider.Provider
-	GetResolver() resolver.DependencyResolver
 
 	Size() tea.WindowSizeMsg
 	SetSize(size tea.WindowSizeMsg)
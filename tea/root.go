@@ -1,12 +1,12 @@
 package tea
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Khan/genqlient/graphql"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
@@ -15,20 +15,20 @@ import (
 )
 
 type rootModel struct {
-	headerComponent    tea.Model
-	global             *cli.GlobalContext
-	dependencyResolver resolver.DependencyResolver
-	currentSize        tea.WindowSizeMsg
+	ctx             context.Context
+	headerComponent tea.Model
+	global          *cli.GlobalContext
+	currentSize     tea.WindowSizeMsg
 }
 
-func newModel(global *cli.GlobalContext) *rootModel {
+func newModel(ctx context.Context, global *cli.GlobalContext) *rootModel {
 	m := &rootModel{
+		ctx:    ctx,
 		global: global,
 		currentSize: tea.WindowSizeMsg{
 			Width:  20,
 			Height: 14,
 		},
-		dependencyResolver: resolver.NewDependencyResolver(global.Provider),
 	}
 
 	m.headerComponent = components.NewHeaderComponent(m)
@@ -66,12 +66,12 @@ func (m *rootModel) GetAPIClient() graphql.Client {
 	return m.global.APIClient
 }
 
-func (m *rootModel) GetProvider() provider.Provider {
-	return m.global.Provider
+func (m *rootModel) GetContext() context.Context {
+	return m.ctx
 }
 
-func (m *rootModel) GetResolver() resolver.DependencyResolver {
-	return m.dependencyResolver
+func (m *rootModel) GetProvider() provider.Provider {
+	return m.global.Provider
 }
 
 func (m *rootModel) Size() tea.WindowSizeMsg {
@@ -94,8 +94,8 @@ func (m *rootModel) GetGlobal() *cli.GlobalContext {
 	return m.global
 }
 
-func RunTea(global *cli.GlobalContext) error {
-	if _, err := tea.NewProgram(scenes.NewMainMenu(newModel(global)), tea.WithAltScreen(), tea.WithMouseCellMotion()).Run(); err != nil {
+func RunTea(ctx context.Context, global *cli.GlobalContext) error {
+	if _, err := tea.NewProgram(scenes.NewMainMenu(newModel(ctx, global)), tea.WithAltScreen(), tea.WithMouseCellMotion()).Run(); err != nil {
 		return fmt.Errorf("internal tea error: %w", err)
 	}
 	return nil
@@ -1,6 +1,8 @@
 package scenes
 
 import (
+	"context"
+	"errors"
 	"sort"
 	"sync"
 
@@ -29,6 +31,7 @@ type modProgress struct {
 type status struct {
 	modProgresses   map[string]modProgress
 	installName     string
+	waiting         string
 	overallProgress utils.GenericProgress
 	done            bool
 }
@@ -40,7 +43,7 @@ type apply struct {
 	updateChannel chan applyUpdate
 	doneChannel   chan bool
 	errorChannel  chan error
-	cancelChannel chan bool
+	cancel        context.CancelFunc
 	title         string
 	status        map[string]status
 	overall       progress.Model
@@ -61,8 +64,9 @@ func NewApply(root components.RootModel, parent tea.Model) tea.Model {
 
 	updateChannel := make(chan applyUpdate)
 	doneChannel := make(chan bool, 1)
-	errorChannel := make(chan error)
-	cancelChannel := make(chan bool, 1)
+	// Every installation sends at most one error, so that none of them is blocked once the scene is left
+	errorChannel := make(chan error, len(root.GetGlobal().Installations.Installations))
+	ctx, cancel := context.WithCancel(root.GetContext())
 
 	model := &apply{
 		root:          root,
@@ -74,7 +78,7 @@ func NewApply(root components.RootModel, parent tea.Model) tea.Model {
 		updateChannel: updateChannel,
 		doneChannel:   doneChannel,
 		errorChannel:  errorChannel,
-		cancelChannel: cancelChannel,
+		cancel:        cancel,
 	}
 
 	var wg sync.WaitGroup
@@ -84,7 +88,7 @@ func NewApply(root components.RootModel, parent tea.Model) tea.Model {
 
 		model.status[installation.Path] = status{
 			modProgresses:   make(map[string]modProgress),
-			installName:     installation.Path,
+			installName:     installation.DisplayName(),
 			overallProgress: utils.GenericProgress{},
 		}
 
@@ -94,27 +98,36 @@ func NewApply(root components.RootModel, parent tea.Model) tea.Model {
 			installUpdateChannel := make(chan cli.InstallUpdate)
 			go func() {
 				for update := range installUpdateChannel {
-					updateChannel <- applyUpdate{
+					// Updates are dropped once the scene is left
+					select {
+					case updateChannel <- applyUpdate{
 						Installation: installation,
 						Update:       update,
+					}:
+					case <-ctx.Done():
 					}
 				}
 			}()
 
-			if err := installation.Install(root.GetGlobal(), installUpdateChannel); err != nil {
+			// Wait for the game to stop, it can be cancelled from the scene
+			if err := installation.Install(ctx, root.GetGlobal(), cli.InstallOptions{Wait: -1}, installUpdateChannel); err != nil {
 				errorChannel <- err
 				return
 			}
 
-			updateChannel <- applyUpdate{
+			select {
+			case updateChannel <- applyUpdate{
 				Installation: installation,
 				Done:         true,
+			}:
+			case <-ctx.Done():
 			}
 		}(installation)
 	}
 
 	go func() {
 		wg.Wait()
+		cancel()
 		doneChannel <- true
 	}()
 
@@ -130,34 +143,26 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch keypress := msg.String(); keypress {
 		case keys.KeyControlC:
+			m.cancel()
 			return m, tea.Quit
 		case keys.KeyQ:
 			fallthrough
 		case keys.KeyEscape:
 			if m.done {
-				if m.parent != nil {
-					return m.parent, m.parent.Init()
-				}
-				return m, tea.Quit
+				return m.exit()
 			}
 
 			m.cancelled = true
 
 			if m.error != nil {
-				if m.parent != nil {
-					return m.parent, m.parent.Init()
-				}
-				return m, tea.Quit
+				return m.exit()
 			}
 
-			m.cancelChannel <- true
+			m.cancel()
 			return m, nil
 		case keys.KeyEnter:
 			if m.done || m.error != nil {
-				if m.parent != nil {
-					return m.parent, m.parent.Init()
-				}
-				return m, tea.Quit
+				return m.exit()
 			}
 			return m, nil
 		}
@@ -173,10 +178,14 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case update := <-m.updateChannel:
 			s := m.status[update.Installation.Path]
 
+			s.waiting = ""
+
 			if update.Done {
 				s.done = true
 			} else {
 				switch update.Update.Type {
+				case cli.InstallUpdateTypeWaiting:
+					s.waiting = update.Update.Message
 				case cli.InstallUpdateTypeOverall:
 					s.overallProgress = update.Update.Progress
 				case cli.InstallUpdateTypeModDownload:
@@ -201,6 +210,9 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status[update.Installation.Path] = s
 			break
 		case err := <-m.errorChannel:
+			if m.cancelled && errors.Is(err, context.Canceled) {
+				break
+			}
 			wrappedErrMessage := wrap.String(err.Error(), int(float64(m.root.Size().Width)*0.8))
 			errorComponent, _ := components.NewErrorComponent(wrappedErrMessage, 0)
 			m.error = errorComponent
@@ -215,6 +227,16 @@ func (m apply) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// exit leaves the scene, cancelling the installations which are still running
+func (m apply) exit() (tea.Model, tea.Cmd) {
+	m.cancel()
+
+	if m.parent != nil {
+		return m.parent, m.parent.Init()
+	}
+	return m, tea.Quit
+}
+
 func (m apply) View() string {
 	strs := make([]string, 0)
 
@@ -256,6 +278,10 @@ func (m apply) View() string {
 			lipgloss.NewStyle().Render(installPath),
 		)))
 
+		if s.waiting != "" {
+			strs = append(strs, lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("214")).Render("Waiting for the game to stop, press q to cancel: "+s.waiting))
+		}
+
 		modReferences := make([]string, 0)
 		for k := range s.modProgresses {
 			modReferences = append(modReferences, k)
@@ -0,0 +1,126 @@
+package installation
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*discoverInstallations)(nil)
+
+type discoverInstallations struct {
+	root   components.RootModel
+	list   list.Model
+	parent tea.Model
+}
+
+func NewDiscoverInstallations(root components.RootModel, parent tea.Model) tea.Model {
+	l := list.New(discoveredToList(root), utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.Title = "Discovered Installations"
+	l.Styles = utils.ListStyles
+	l.SetSize(l.Width(), l.Height())
+	l.StatusMessageLifetime = time.Second * 3
+	l.KeyMap.Quit.SetHelp("q", "back")
+
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "add")),
+		}
+	}
+
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+
+	return &discoverInstallations{
+		root:   root,
+		list:   l,
+		parent: parent,
+	}
+}
+
+func (m discoverInstallations) Init() tea.Cmd {
+	return nil
+}
+
+func (m discoverInstallations) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.SettingFilter() {
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case "q":
+			if m.parent != nil {
+				m.parent.Update(m.root.Size())
+				return m.parent, updateInstallationListCmd
+			}
+			return m, tea.Quit
+		case keys.KeyEnter:
+			i, ok := m.list.SelectedItem().(utils.SimpleItemExtra[discoverInstallations, *cli.DiscoveredInstallation])
+			if !ok {
+				return m, nil
+			}
+
+			newInstall, err := m.root.GetGlobal().Installations.AddInstallation(m.root.GetContext(), m.root.GetGlobal(), i.Extra.Path, m.root.GetGlobal().Profiles.SelectedProfile)
+			if err != nil {
+				slog.Error("failed to add installation", slog.Any("err", err))
+				return m, m.list.NewStatusMessage("failed to add installation: " + err.Error())
+			}
+
+			if m.root.GetCurrentInstallation() == nil {
+				if err := m.root.SetCurrentInstallation(newInstall); err != nil {
+					return m, m.list.NewStatusMessage(err.Error())
+				}
+			}
+
+			m.list.RemoveItem(m.list.Index())
+			return m, m.list.NewStatusMessage("added " + i.Extra.Path)
+		}
+	case tea.WindowSizeMsg:
+		top, right, bottom, left := lipgloss.NewStyle().Margin(m.root.Height(), 2, 0).GetMargin()
+		m.list.SetSize(msg.Width-left-right, msg.Height-top-bottom)
+		m.root.SetSize(msg)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m discoverInstallations) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.list.View())
+}
+
+func discoveredToList(root components.RootModel) []list.Item {
+	var items []list.Item
+
+	for _, installation := range root.GetGlobal().Installations.Discover(root.GetContext(), root.GetGlobal()) {
+		if installation.Added {
+			continue
+		}
+
+		items = append(items, utils.SimpleItemExtra[discoverInstallations, *cli.DiscoveredInstallation]{
+			SimpleItem: utils.SimpleItem[discoverInstallations]{
+				ItemTitle: installation.Path + " - " + installation.Platform + " (" + installation.Source + ")",
+			},
+			Extra: installation,
+		})
+	}
+
+	return items
+}
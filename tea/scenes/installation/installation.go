@@ -2,6 +2,7 @@ package installation
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -63,7 +64,7 @@ func NewInstallation(root components.RootModel, parent tea.Model, installationDa
 	model.list = list.New(items, utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
 	model.list.SetShowStatusBar(false)
 	model.list.SetFilteringEnabled(false)
-	model.list.Title = fmt.Sprintf("Installation: %s", installationData.Path)
+	model.list.Title = fmt.Sprintf("Installation: %s", installationData.DisplayName())
 	model.list.Styles = utils.ListStyles
 	model.list.SetSize(model.list.Width(), model.list.Height())
 	model.list.StatusMessageLifetime = time.Second * 3
@@ -124,7 +125,7 @@ func (m installation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.root.SetSize(msg)
 	case updateInstallationNames:
 		m.hadRenamed = true
-		m.list.Title = fmt.Sprintf("Installation: %s", m.installation.Path)
+		m.list.Title = fmt.Sprintf("Installation: %s", m.installation.DisplayName())
 	case components.ErrorComponentTimeoutMsg:
 		m.error = nil
 	}
@@ -133,12 +134,39 @@ func (m installation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m installation) View() string {
+	views := []string{m.root.View()}
+	height := m.root.Height()
+
 	if m.error != nil {
 		err := m.error.View()
-		m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height()-lipgloss.Height(err))
-		return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), err, m.list.View())
+		views = append(views, err)
+		height += lipgloss.Height(err)
+	}
+
+	if details := m.details(); details != "" {
+		views = append(views, details)
+		height += lipgloss.Height(details)
+	}
+
+	m.list.SetSize(m.list.Width(), m.root.Size().Height-height)
+	return lipgloss.JoinVertical(lipgloss.Left, append(views, m.list.View())...)
+}
+
+// details renders the path, tags and notes of a named or tagged installation
+func (m installation) details() string {
+	if m.installation.Name == "" && len(m.installation.Tags) == 0 && m.installation.Notes == "" {
+		return ""
+	}
+
+	lines := []string{utils.LabelStyle.Render("Path: ") + m.installation.Path}
+
+	if len(m.installation.Tags) > 0 {
+		lines = append(lines, utils.LabelStyle.Render("Tags: ")+strings.Join(m.installation.Tags, ", "))
+	}
+
+	if m.installation.Notes != "" {
+		lines = append(lines, utils.LabelStyle.Render("Notes: ")+m.installation.Notes)
 	}
 
-	m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height())
-	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.list.View())
+	return strings.Join(lines, "\n")
 }
@@ -1,6 +1,8 @@
 package installation
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -33,6 +35,7 @@ func NewInstallations(root components.RootModel, parent tea.Model) tea.Model {
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new installation")),
+			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "discover installations")),
 			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
 		}
 	}
@@ -63,6 +66,9 @@ func (m installations) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "n":
 			newModel := NewNewInstallation(m.root, m)
 			return newModel, newModel.Init()
+		case "d":
+			newModel := NewDiscoverInstallations(m.root, m)
+			return newModel, newModel.Init()
 		case keys.KeyControlC:
 			return m, tea.Quit
 		case "q":
@@ -115,8 +121,18 @@ func installationsToList(root components.RootModel) []list.Item {
 	i := 0
 	for _, installation := range root.GetGlobal().Installations.Installations {
 		temp := installation
+
+		title := temp.Path
+		if temp.Name != "" {
+			title = temp.Name + " (" + temp.Path + ")"
+		}
+
+		if len(temp.Tags) > 0 {
+			title += " [" + strings.Join(temp.Tags, ", ") + "]"
+		}
+
 		items[i] = utils.SimpleItem[installations]{
-			ItemTitle: temp.Path,
+			ItemTitle: title,
 			Activate: func(msg tea.Msg, currentModel installations) (tea.Model, tea.Cmd) {
 				newModel := NewInstallation(root, currentModel, temp)
 				return newModel, newModel.Init()
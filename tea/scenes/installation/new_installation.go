@@ -83,7 +83,7 @@ func (m newInstallation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case keys.KeyEscape:
 			return m.parent, nil
 		case keys.KeyEnter:
-			newInstall, err := m.root.GetGlobal().Installations.AddInstallation(m.root.GetGlobal(), m.input.Value(), m.root.GetGlobal().Profiles.SelectedProfile)
+			newInstall, err := m.root.GetGlobal().Installations.AddInstallation(m.root.GetContext(), m.root.GetGlobal(), m.input.Value(), m.root.GetGlobal().Profiles.SelectedProfile)
 			if err != nil {
 				errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
 				m.error = errorComponent
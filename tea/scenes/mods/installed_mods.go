@@ -1,7 +1,6 @@
 package mods
 
 import (
-	"context"
 	"sort"
 	"time"
 
@@ -111,7 +110,7 @@ func (m installedModsList) LoadModData() {
 			i++
 		}
 
-		mods, err := m.root.GetProvider().Mods(context.TODO(), ficsit.ModFilter{
+		mods, err := m.root.GetProvider().Mods(m.root.GetContext(), ficsit.ModFilter{
 			References: references,
 		})
 		if err != nil {
@@ -3,7 +3,6 @@ package mods
 // cspell:disable
 
 import (
-	"context"
 	"log/slog"
 	"strconv"
 	"strings"
@@ -96,7 +95,7 @@ func NewModInfo(root components.RootModel, parent tea.Model, mod utils.Mod) tea.
 	model.help.Width = root.Size().Width
 
 	go func() {
-		fullMod, err := root.GetProvider().GetMod(context.TODO(), mod.Reference)
+		fullMod, err := root.GetProvider().GetMod(root.GetContext(), mod.Reference)
 		if err != nil {
 			model.modError <- err.Error()
 			return
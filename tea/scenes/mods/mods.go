@@ -1,7 +1,6 @@
 package mods
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -221,7 +220,7 @@ func NewMods(root components.RootModel, parent tea.Model) tea.Model {
 		allMods := make([]ficsit.ModsModsGetModsModsMod, 0)
 		offset := 0
 		for {
-			mods, err := root.GetProvider().Mods(context.TODO(), ficsit.ModFilter{
+			mods, err := root.GetProvider().Mods(root.GetContext(), ficsit.ModFilter{
 				Limit:    100,
 				Offset:   offset,
 				Order_by: ficsit.ModFieldsLastVersionDate,
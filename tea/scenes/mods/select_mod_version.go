@@ -1,7 +1,6 @@
 package mods
 
 import (
-	"context"
 	"fmt"
 	"time"
 
@@ -52,7 +51,7 @@ func NewModVersionList(root components.RootModel, parent tea.Model, mod utils.Mo
 
 	go func() {
 		items := make([]list.Item, 0)
-		versions, err := root.GetProvider().ModVersionsWithDependencies(context.TODO(), mod.Reference)
+		versions, err := root.GetProvider().ModVersionsWithDependencies(root.GetContext(), mod.Reference)
 		if err != nil {
 			m.err <- err.Error()
 			return
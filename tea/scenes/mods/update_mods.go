@@ -1,7 +1,6 @@
 package mods
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -97,7 +96,7 @@ func (m updateModsList) LoadModData() {
 	currentInstallation := m.root.GetCurrentInstallation()
 	currentProfile := m.root.GetCurrentProfile()
 
-	currentLockfile, err := m.root.GetCurrentInstallation().LockFile(m.root.GetGlobal())
+	currentLockfile, err := m.root.GetCurrentInstallation().LockFile(m.root.GetContext(), m.root.GetGlobal())
 	if err != nil {
 		return
 	}
@@ -105,12 +104,12 @@ func (m updateModsList) LoadModData() {
 		return
 	}
 
-	gameVersion, err := currentInstallation.GetGameVersion(m.root.GetGlobal())
+	gameVersion, err := currentInstallation.GetGameVersion(m.root.GetContext(), m.root.GetGlobal())
 	if err != nil {
 		return
 	}
 
-	updatedLockfile, err := currentProfile.Resolve(m.root.GetResolver(), nil, gameVersion)
+	updatedLockfile, err := currentProfile.Resolve(m.root.GetProvider(), nil, gameVersion)
 	if err != nil {
 		return
 	}
@@ -174,7 +173,7 @@ func (m updateModsList) loadModNames(items []list.Item) {
 		i++
 	}
 
-	mods, err := ficsit.Mods(context.TODO(), m.root.GetAPIClient(), ficsit.ModFilter{
+	mods, err := ficsit.Mods(m.root.GetContext(), m.root.GetAPIClient(), ficsit.ModFilter{
 		References: references,
 	})
 	if err != nil {
@@ -257,7 +256,7 @@ func (m updateModsList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case keys.KeyEnter:
 			if len(m.selectedMods) > 0 {
-				err := m.root.GetCurrentInstallation().UpdateMods(m.root.GetGlobal(), m.selectedMods)
+				err := m.root.GetCurrentInstallation().UpdateMods(m.root.GetContext(), m.root.GetGlobal(), m.selectedMods)
 				if err != nil {
 					m.err <- err.Error()
 					return m, nil
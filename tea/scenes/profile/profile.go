@@ -46,6 +46,13 @@ func NewProfile(root components.RootModel, parent tea.Model, profileData *cli.Pr
 				return currentModel.parent, nil
 			},
 		},
+		utils.SimpleItem[profile]{
+			ItemTitle: "Required Targets",
+			Activate: func(msg tea.Msg, currentModel profile) (tea.Model, tea.Cmd) {
+				newModel := NewProfileTargets(root, currentModel, profileData)
+				return newModel, newModel.Init()
+			},
+		},
 	}
 
 	if profileData.Name != cli.DefaultProfileName {
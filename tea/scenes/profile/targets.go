@@ -0,0 +1,135 @@
+package profile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/tea/components"
+	"github.com/satisfactorymodding/ficsit-cli/tea/scenes/keys"
+	"github.com/satisfactorymodding/ficsit-cli/tea/utils"
+)
+
+var _ tea.Model = (*profileTargets)(nil)
+
+type profileTargets struct {
+	list    list.Model
+	root    components.RootModel
+	parent  tea.Model
+	profile *cli.Profile
+	error   *components.ErrorComponent
+}
+
+func NewProfileTargets(root components.RootModel, parent tea.Model, profileData *cli.Profile) tea.Model {
+	model := profileTargets{
+		root:    root,
+		parent:  parent,
+		profile: profileData,
+	}
+
+	model.list = list.New(model.targetsToList(), utils.NewItemDelegate(), root.Size().Width, root.Size().Height-root.Height())
+	model.list.SetShowStatusBar(false)
+	model.list.SetFilteringEnabled(false)
+	model.list.Title = fmt.Sprintf("Required Targets: %s", profileData.Name)
+	model.list.Styles = utils.ListStyles
+	model.list.SetSize(model.list.Width(), model.list.Height())
+	model.list.KeyMap.Quit.SetHelp("q", "back")
+	model.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "toggle")),
+		}
+	}
+
+	return model
+}
+
+func (m profileTargets) targetsToList() []list.Item {
+	items := make([]list.Item, len(cli.AllTargets))
+	for i, target := range cli.AllTargets {
+		target := target
+
+		checkbox := "[ ] "
+		if m.profile.HasRequiredTarget(target) {
+			checkbox = "[x] "
+		}
+
+		items[i] = utils.SimpleItem[profileTargets]{
+			ItemTitle: checkbox + string(target),
+			Activate: func(msg tea.Msg, currentModel profileTargets) (tea.Model, tea.Cmd) {
+				if err := currentModel.profile.ToggleRequiredTarget(target); err != nil {
+					errorComponent, cmd := components.NewErrorComponent(err.Error(), time.Second*5)
+					currentModel.error = errorComponent
+					return currentModel, cmd
+				}
+
+				cmd := currentModel.list.SetItems(currentModel.targetsToList())
+				return currentModel, cmd
+			},
+		}
+	}
+
+	return items
+}
+
+func (m profileTargets) Init() tea.Cmd {
+	return nil
+}
+
+func (m profileTargets) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case keys.KeyControlC:
+			return m, tea.Quit
+		case "q":
+			if m.parent != nil {
+				m.parent.Update(m.root.Size())
+				return m.parent, nil
+			}
+			return m, nil
+		case keys.KeyEnter:
+			i, ok := m.list.SelectedItem().(utils.SimpleItem[profileTargets])
+			if ok {
+				if i.Activate != nil {
+					newModel, cmd := i.Activate(msg, m)
+					if newModel != nil || cmd != nil {
+						if newModel == nil {
+							newModel = m
+						}
+						return newModel, cmd
+					}
+					return m, nil
+				}
+			}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		top, right, bottom, left := lipgloss.NewStyle().Margin(2, 2).GetMargin()
+		m.list.SetSize(msg.Width-left-right, msg.Height-top-bottom)
+		m.root.SetSize(msg)
+	case components.ErrorComponentTimeoutMsg:
+		m.error = nil
+	}
+
+	return m, nil
+}
+
+func (m profileTargets) View() string {
+	if m.error != nil {
+		err := m.error.View()
+		m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height()-lipgloss.Height(err))
+		return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), err, m.list.View())
+	}
+
+	m.list.SetSize(m.list.Width(), m.root.Size().Height-m.root.Height())
+	return lipgloss.JoinVertical(lipgloss.Left, m.root.View(), m.list.View())
+}
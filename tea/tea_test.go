@@ -1,6 +1,7 @@
 package tea
 
 import (
+	"context"
 	"errors"
 	"io"
 	"os"
@@ -38,13 +39,13 @@ func TestTea(t *testing.T) {
 
 	ctx.Provider = cli.MockProvider{}
 
-	err = ctx.Wipe()
+	err = ctx.Wipe(context.Background())
 	testza.AssertNoError(t, err)
 
 	err = ctx.ReInit()
 	testza.AssertNoError(t, err)
 
-	root := newModel(ctx)
+	root := newModel(context.Background(), ctx)
 	m := scenes.NewMainMenu(root)
 
 	tm := teatest.NewTestModel(
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	httpTransport     http.RoundTripper
+	httpTransportOnce sync.Once
+)
+
+// HTTPTransport returns the shared transport, which applies the configured connect-timeout and read-timeout
+//
+// The read timeout applies to waiting for the response headers, and to every read of the response body,
+// so slow but progressing downloads are not interrupted
+func HTTPTransport() http.RoundTripper {
+	httpTransportOnce.Do(func() {
+		connectTimeout := viper.GetDuration("connect-timeout")
+		readTimeout := viper.GetDuration("read-timeout")
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.TLSHandshakeTimeout = connectTimeout
+		transport.ResponseHeaderTimeout = readTimeout
+
+		httpTransport = &readTimeoutTransport{
+			wrapped:     transport,
+			readTimeout: readTimeout,
+		}
+	})
+
+	return httpTransport
+}
+
+// HTTPClient returns a client using the shared transport
+func HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: HTTPTransport(),
+	}
+}
+
+type readTimeoutTransport struct {
+	wrapped     http.RoundTripper
+	readTimeout time.Duration
+}
+
+func (t *readTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.readTimeout <= 0 {
+		return t.wrapped.RoundTrip(req) //nolint:wrapcheck
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+
+	resp, err := t.wrapped.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err //nolint:wrapcheck
+	}
+
+	resp.Body = &readTimeoutBody{
+		ReadCloser: resp.Body,
+		timer:      time.AfterFunc(t.readTimeout, cancel),
+		timeout:    t.readTimeout,
+		cancel:     cancel,
+	}
+
+	return resp, nil
+}
+
+// readTimeoutBody cancels the request if no read completes within the timeout
+type readTimeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+func (b *readTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Reset(b.timeout)
+	return n, err //nolint:wrapcheck
+}
+
+func (b *readTimeoutBody) Close() error {
+	b.timer.Stop()
+	defer b.cancel()
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
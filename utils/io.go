@@ -2,14 +2,21 @@ package utils
 
 import (
 	"archive/zip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 
+	"github.com/spf13/viper"
+
 	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 )
 
@@ -22,16 +29,63 @@ func SHA256Data(f io.Reader) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func ExtractMod(reader *zip.Reader, location string, hash string, updates chan<- GenericProgress, d disk.Disk) error {
+// ModManifest lists the files extracted for a mod,
+// so that updating the mod only writes the files which changed between versions
+type ModManifest struct {
+	Files map[string]ModManifestFile `json:"files"`
+}
+
+type ModManifestFile struct {
+	Size  uint64 `json:"size"`
+	CRC32 uint32 `json:"crc32"`
+}
+
+func newModManifest(reader *zip.Reader) ModManifest {
+	manifest := ModManifest{
+		Files: make(map[string]ModManifestFile, len(reader.File)),
+	}
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		manifest.Files[file.Name] = ModManifestFile{
+			Size:  file.UncompressedSize64,
+			CRC32: file.CRC32,
+		}
+	}
+
+	return manifest
+}
+
+// unchanged returns whether the file is already extracted with the same content
+func (m *ModManifest) unchanged(file *zip.File) bool {
+	if m == nil {
+		return false
+	}
+
+	existing, ok := m.Files[file.Name]
+	return ok && existing.Size == file.UncompressedSize64 && existing.CRC32 == file.CRC32
+}
+
+// ExtractMod extracts the mod archive to the location, unless it is already extracted with the same hash
+//
+// If a previous version of the mod was extracted with a manifest, only the changed files are written,
+// and the files which are not part of the new version are removed.
+//
+// archive is the raw zip file, which is uploaded as a whole to disks that can extract it themselves if remote-extract is enabled
+func ExtractMod(ctx context.Context, reader *zip.Reader, archive io.Reader, location string, hash string, updates chan<- GenericProgress, d disk.Disk) error {
 	hashFile := filepath.Join(location, ".smm")
+	manifestFile := filepath.Join(location, ".smm-manifest")
 
-	exists, err := d.Exists(hashFile)
+	exists, err := d.Exists(ctx, hashFile)
 	if err != nil {
 		return err
 	}
 
 	if exists {
-		hashBytes, err := d.Read(hashFile)
+		hashBytes, err := d.Read(ctx, hashFile)
 		if err != nil {
 			return fmt.Errorf("failed to read .smm mod hash file: %w", err)
 		}
@@ -41,34 +95,208 @@ func ExtractMod(reader *zip.Reader, location string, hash string, updates chan<-
 		}
 	}
 
-	exists, err = d.Exists(location)
+	exists, err = d.Exists(ctx, location)
 	if err != nil {
 		return err
 	}
 
+	var previous *ModManifest
+
 	if exists {
-		if err := d.Remove(location); err != nil {
-			return fmt.Errorf("failed to remove directory: %s: %w", location, err)
+		// Remote extraction replaces the whole directory anyway
+		if !canExtractRemotely(archive, d) {
+			previous = readModManifest(ctx, manifestFile, d)
 		}
 
-		if err := d.MkDir(location); err != nil {
-			return fmt.Errorf("failed to create mod directory: %s: %w", location, err)
+		if previous == nil {
+			if err := d.Remove(ctx, location); err != nil {
+				return fmt.Errorf("failed to remove directory: %s: %w", location, err)
+			}
+
+			if err := d.MkDir(ctx, location); err != nil {
+				return fmt.Errorf("failed to create mod directory: %s: %w", location, err)
+			}
+		} else {
+			// The previous version is no longer installed as a whole once files start changing
+			for _, file := range []string{hashFile, manifestFile} {
+				if err := removeIfExists(ctx, file, d); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
+	if err := extractModFiles(ctx, reader, archive, location, previous, updates, d); err != nil {
+		// Without its .smm file, a partially extracted mod would never be cleaned up, even if the extraction was cancelled
+		if removeErr := d.Remove(context.WithoutCancel(ctx), location); removeErr != nil {
+			slog.Warn("failed to remove partially extracted mod", slog.String("location", location), slog.Any("err", removeErr))
+		}
+		return err
+	}
+
+	manifestJSON, err := json.Marshal(newModManifest(reader))
+	if err != nil {
+		return fmt.Errorf("failed to serialize mod manifest: %w", err)
+	}
+
+	if err := d.Write(ctx, manifestFile, manifestJSON); err != nil {
+		return fmt.Errorf("failed to write .smm-manifest mod manifest file: %w", err)
+	}
+
+	if err := d.Write(ctx, hashFile, []byte(hash)); err != nil {
+		return fmt.Errorf("failed to write .smm mod hash file: %w", err)
+	}
+
+	return nil
+}
+
+// AdoptMod marks a mod extracted by other tools as extracted from the archive,
+// if all files of the archive are extracted at the location unchanged.
+// Returns false if a file is missing or differs, leaving the mod unmarked.
+func AdoptMod(ctx context.Context, reader *zip.Reader, location string, hash string, d disk.Disk) (bool, error) {
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		matches, err := extractedFileMatches(ctx, filepath.Join(location, file.Name), file, d)
+		if err != nil {
+			return false, err
+		}
+
+		if !matches {
+			slog.Info("extracted file differs from archive", slog.String("location", location), slog.String("file", file.Name))
+			return false, nil
+		}
+	}
+
+	manifestJSON, err := json.Marshal(newModManifest(reader))
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize mod manifest: %w", err)
+	}
+
+	if err := d.Write(ctx, filepath.Join(location, ".smm-manifest"), manifestJSON); err != nil {
+		return false, fmt.Errorf("failed to write .smm-manifest mod manifest file: %w", err)
+	}
+
+	if err := d.Write(ctx, filepath.Join(location, ".smm"), []byte(hash)); err != nil {
+		return false, fmt.Errorf("failed to write .smm mod hash file: %w", err)
+	}
+
+	return true, nil
+}
+
+func extractedFileMatches(ctx context.Context, path string, file *zip.File, d disk.Disk) (bool, error) {
+	exists, err := d.Exists(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
+		return false, nil
+	}
+
+	extracted, err := d.OpenReader(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer extracted.Close()
+
+	hash := crc32.NewIEEE()
+	size, err := io.Copy(hash, extracted)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return uint64(size) == file.UncompressedSize64 && hash.Sum32() == file.CRC32, nil
+}
+
+// ExtractArchive extracts all files of the archive to the location, which is expected to be empty
+//
+// archive is the raw zip file, which is uploaded as a whole to disks that can extract it themselves if remote-extract is enabled
+func ExtractArchive(ctx context.Context, reader *zip.Reader, archive io.Reader, location string, updates chan<- GenericProgress, d disk.Disk) error {
+	return extractModFiles(ctx, reader, archive, location, nil, updates, d)
+}
+
+// readModManifest returns the manifest of the extracted mod, or nil if it has none
+func readModManifest(ctx context.Context, manifestFile string, d disk.Disk) *ModManifest {
+	exists, err := d.Exists(ctx, manifestFile)
+	if err != nil || !exists {
+		return nil
+	}
+
+	manifestJSON, err := d.Read(ctx, manifestFile)
+	if err != nil {
+		slog.Warn("failed to read mod manifest, extracting all files", slog.String("path", manifestFile), slog.Any("err", err))
+		return nil
+	}
+
+	var manifest ModManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil || manifest.Files == nil {
+		slog.Warn("invalid mod manifest, extracting all files", slog.String("path", manifestFile), slog.Any("err", err))
+		return nil
+	}
+
+	return &manifest
+}
+
+func removeIfExists(ctx context.Context, path string, d disk.Disk) error {
+	exists, err := d.Exists(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return nil
+	}
+
+	if err := d.Remove(ctx, path); err != nil {
+		return fmt.Errorf("failed to remove: %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func extractModFiles(ctx context.Context, reader *zip.Reader, archive io.Reader, location string, previous *ModManifest, updates chan<- GenericProgress, d disk.Disk) error {
 	totalSize := int64(0)
 
 	for _, file := range reader.File {
 		totalSize += int64(file.UncompressedSize64)
 	}
 
+	if previous == nil {
+		extracted, err := extractRemotely(ctx, reader, archive, location, d)
+		if err != nil {
+			return err
+		}
+
+		if extracted {
+			if updates != nil {
+				updates <- GenericProgress{Completed: totalSize, Total: totalSize}
+			}
+
+			return nil
+		}
+	}
+
 	totalExtracted := int64(0)
+	skipped := 0
 
 	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("extraction cancelled: %w", err)
+		}
+
+		if previous.unchanged(file) {
+			totalExtracted += int64(file.UncompressedSize64)
+			skipped++
+			continue
+		}
+
 		if !file.FileInfo().IsDir() {
 			outFileLocation := filepath.Join(location, file.Name)
 
-			if err := d.MkDir(filepath.Dir(outFileLocation)); err != nil {
+			if err := d.MkDir(ctx, filepath.Dir(outFileLocation)); err != nil {
 				return fmt.Errorf("failed to create mod directory: %s: %w", location, err)
 			}
 
@@ -90,7 +318,7 @@ func ExtractMod(reader *zip.Reader, location string, hash string, updates chan<-
 				}()
 			}
 
-			if err := writeZipFile(outFileLocation, file, d, fileUpdates); err != nil {
+			if err := writeZipFile(ctx, outFileLocation, file, d, fileUpdates); err != nil {
 				channelUsers.Wait()
 				return err
 			}
@@ -101,8 +329,19 @@ func ExtractMod(reader *zip.Reader, location string, hash string, updates chan<-
 		}
 	}
 
-	if err := d.Write(hashFile, []byte(hash)); err != nil {
-		return fmt.Errorf("failed to write .smm mod hash file: %w", err)
+	if previous != nil {
+		current := newModManifest(reader)
+		for name := range previous.Files {
+			if _, ok := current.Files[name]; ok {
+				continue
+			}
+
+			if err := removeIfExists(ctx, filepath.Join(location, name), d); err != nil {
+				return fmt.Errorf("failed to remove file of previous version: %w", err)
+			}
+		}
+
+		slog.Info("updated mod files", slog.String("location", location), slog.Int("unchanged", skipped), slog.Int("total", len(current.Files)))
 	}
 
 	if updates != nil {
@@ -112,12 +351,55 @@ func ExtractMod(reader *zip.Reader, location string, hash string, updates chan<-
 	return nil
 }
 
-func writeZipFile(outFileLocation string, file *zip.File, d disk.Disk, updates chan<- GenericProgress) error {
+// extractRemotely lets the disk extract the archive if it supports it.
+// Returns false if the files must be extracted one by one instead.
+func extractRemotely(ctx context.Context, reader *zip.Reader, archive io.Reader, location string, d disk.Disk) (bool, error) {
+	if !canExtractRemotely(archive, d) {
+		return false, nil
+	}
+
+	err := d.(disk.Extractor).ExtractZip(ctx, archive, reader.File, location)
+	if err == nil {
+		return true, nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return false, fmt.Errorf("extraction cancelled: %w", ctxErr)
+	}
+
+	if errors.Is(err, errors.ErrUnsupported) {
+		return false, nil
+	}
+
+	slog.Warn("remote extraction failed, extracting files one by one", slog.String("location", location), slog.Any("err", err))
+
+	// Start over from an empty directory, as files are not truncated when extracted one by one
+	if err := d.Remove(ctx, location); err != nil {
+		return false, fmt.Errorf("failed to remove directory: %s: %w", location, err)
+	}
+
+	if err := d.MkDir(ctx, location); err != nil {
+		return false, fmt.Errorf("failed to create mod directory: %s: %w", location, err)
+	}
+
+	return false, nil
+}
+
+func canExtractRemotely(archive io.Reader, d disk.Disk) bool {
+	if archive == nil || !viper.GetBool("remote-extract") {
+		return false
+	}
+
+	_, ok := d.(disk.Extractor)
+	return ok
+}
+
+func writeZipFile(ctx context.Context, outFileLocation string, file *zip.File, d disk.Disk, updates chan<- GenericProgress) error {
 	if updates != nil {
 		defer close(updates)
 	}
 
-	outFile, err := d.Open(outFileLocation, os.O_CREATE|os.O_RDWR)
+	outFile, err := d.Open(ctx, outFileLocation, os.O_CREATE|os.O_RDWR|os.O_TRUNC)
 	if err != nil {
 		return fmt.Errorf("failed to write to file: %s: %w", outFileLocation, err)
 	}
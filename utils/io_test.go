@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// countingDisk records the files opened for writing
+type countingDisk struct {
+	disk.Disk
+	root   string
+	opened []string
+}
+
+func (d *countingDisk) Open(ctx context.Context, path string, flag int) (io.WriteCloser, error) {
+	rel, _ := filepath.Rel(d.root, path)
+	d.opened = append(d.opened, filepath.ToSlash(rel))
+	return d.Disk.Open(ctx, path, flag) //nolint:wrapcheck
+}
+
+func testModZip(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, name := range names {
+		w, err := writer.Create(name)
+		testza.AssertNoError(t, err)
+		_, err = w.Write([]byte(files[name]))
+		testza.AssertNoError(t, err)
+	}
+	testza.AssertNoError(t, writer.Close())
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	testza.AssertNoError(t, err)
+
+	return reader
+}
+
+func TestExtractModDeltaSync(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	local, err := disk.FromPath(root)
+	testza.AssertNoError(t, err)
+
+	d := &countingDisk{Disk: local, root: filepath.Join(root, "Mod")}
+	location := filepath.Join(root, "Mod")
+
+	v1 := testModZip(t, map[string]string{
+		"Mod.uplugin":     "1.0.0",
+		"Content/a.pak":   "unchanged",
+		"Content/old.pak": "removed",
+	})
+	testza.AssertNoError(t, ExtractMod(ctx, v1, nil, location, "v1", nil, d))
+	testza.AssertEqual(t, []string{"Content/a.pak", "Content/old.pak", "Mod.uplugin"}, d.opened)
+
+	d.opened = nil
+
+	v2 := testModZip(t, map[string]string{
+		"Mod.uplugin":     "2.0.0",
+		"Content/a.pak":   "unchanged",
+		"Content/new.pak": "added",
+	})
+	testza.AssertNoError(t, ExtractMod(ctx, v2, nil, location, "v2", nil, d))
+	testza.AssertEqual(t, []string{"Content/new.pak", "Mod.uplugin"}, d.opened)
+
+	uplugin, err := os.ReadFile(filepath.Join(location, "Mod.uplugin"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []byte("2.0.0"), uplugin)
+
+	_, err = os.Stat(filepath.Join(location, "Content", "a.pak"))
+	testza.AssertNoError(t, err)
+
+	_, err = os.Stat(filepath.Join(location, "Content", "old.pak"))
+	testza.AssertTrue(t, os.IsNotExist(err))
+
+	hash, err := os.ReadFile(filepath.Join(location, ".smm"))
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, []byte("v2"), hash)
+
+	// Nothing is written when the same version is extracted again
+	d.opened = nil
+	testza.AssertNoError(t, ExtractMod(ctx, v2, nil, location, "v2", nil, d))
+	testza.AssertEqual(t, 0, len(d.opened))
+}
+
+func TestExtractModWithoutManifest(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	d, err := disk.FromPath(root)
+	testza.AssertNoError(t, err)
+
+	// A mod extracted before manifests existed
+	location := filepath.Join(root, "Mod")
+	testza.AssertNoError(t, os.MkdirAll(location, 0o777))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(location, ".smm"), []byte("v1"), 0o666))
+	testza.AssertNoError(t, os.WriteFile(filepath.Join(location, "stale.pak"), []byte("stale"), 0o666))
+
+	v2 := testModZip(t, map[string]string{
+		"Mod.uplugin": "2.0.0",
+	})
+	testza.AssertNoError(t, ExtractMod(ctx, v2, nil, location, "v2", nil, d))
+
+	_, err = os.Stat(filepath.Join(location, "stale.pak"))
+	testza.AssertTrue(t, os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join(location, ".smm-manifest"))
+	testza.AssertNoError(t, err)
+}